@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"net/http"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/data"
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure"
+	"git.emercury.dev/emercury/senderscore/api/internal/usecase"
+	"git.emercury.dev/emercury/senderscore/api/pkg/config"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// newAlerter returns nil when neither webhooks nor group channels are
+// enabled, which disables alerts.
+func newAlerter(cfg *config.Config, db *gorm.DB) usecase.Alerter {
+	notifier := newAlertNotifier(cfg, db)
+	if notifier == nil {
+		return nil
+	}
+
+	rules := domain.AlertRules{
+		ScoreBelow:  cfg.Alert.ScoreBelow,
+		ScoreDrop:   cfg.Alert.ScoreDrop,
+		SpamTraps:   cfg.Alert.SpamTraps,
+		Blocklisted: cfg.Alert.Blocklisted,
+	}
+
+	return usecase.NewAlerter(rules, data.NewAlertStateRepository(db), notifier)
+}
+
+func newAlertNotifier(cfg *config.Config, db *gorm.DB) domain.Notifier {
+	client := &http.Client{Timeout: cfg.Alert.WebhookTimeout}
+
+	var notifiers []domain.Notifier
+	if urls := cfg.Alert.GetWebhookURLs(); len(urls) > 0 {
+		if cfg.Alert.WebhookSecret == "" {
+			logrus.Warn("ALERT_WEBHOOK_SECRET is empty, webhooks will be signed with an empty key")
+		}
+		notifiers = append(notifiers, infrastructure.NewWebhookNotifier(urls, cfg.Alert.WebhookSecret, client))
+	}
+	if cfg.Alert.GroupChannels {
+		notifiers = append(notifiers, usecase.NewGroupChannelNotifier(
+			data.NewNotificationChannelRepository(db),
+			newChannelSenders(cfg, client),
+		))
+	}
+
+	if len(notifiers) == 0 {
+		return nil
+	}
+	return usecase.NewMultiNotifier(notifiers...)
+}
+
+// newChannelSenders registers the email sender only when SMTP_HOST is set.
+func newChannelSenders(cfg *config.Config, client *http.Client) map[string]domain.ChannelSender {
+	senders := map[string]domain.ChannelSender{
+		domain.ChannelTypeChat: infrastructure.NewChatSender(client),
+	}
+	if cfg.SMTP.Host != "" {
+		senders[domain.ChannelTypeEmail] = infrastructure.NewEmailSender(infrastructure.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+		})
+	}
+	return senders
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure/senderscore"
+	"git.emercury.dev/emercury/senderscore/api/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+type bulkUpdateSummary struct {
+	Total     int
+	Refreshed int64
+	Failed    int64
+	Skipped   int64
+}
+
+func runBulkUpdate(
+	ctx context.Context,
+	ipUC usecase.IPUseCase,
+	provider domain.ScoreProvider,
+	ips []*usecase.IPDTO,
+	concurrency int,
+	lease time.Duration,
+	fetchTimeout time.Duration,
+) *bulkUpdateSummary {
+	summary := &bulkUpdateSummary{Total: len(ips)}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// Once senderscore.org starts blocking us, every further request only
+	// makes things worse, so the rest of the batch is skipped.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan *usecase.IPDTO)
+	var processed int64
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ip := range jobs {
+				if ctx.Err() != nil {
+					atomic.AddInt64(&summary.Skipped, 1)
+					continue
+				}
+
+				// Each IP is claimed right before its fetch, so IPs that a worker
+				// or another update is processing right now are skipped.
+				claimed, err := ipUC.ClaimIP(ctx, ip.ID, lease)
+				if err != nil || !claimed {
+					done := atomic.AddInt64(&processed, 1)
+					logger := logrus.WithFields(logrus.Fields{
+						"ip":       ip.IP,
+						"progress": done,
+						"total":    summary.Total,
+					})
+					if err != nil {
+						logger.WithError(err).Error("Failed to claim IP")
+						atomic.AddInt64(&summary.Failed, 1)
+					} else {
+						logger.Info("IP is claimed by another process, skipping")
+						atomic.AddInt64(&summary.Skipped, 1)
+					}
+					continue
+				}
+
+				_, err = refreshIP(ctx, ipUC, provider, ip.IP, fetchTimeout)
+				done := atomic.AddInt64(&processed, 1)
+				fields := logrus.Fields{
+					"ip":       ip.IP,
+					"progress": done,
+					"total":    summary.Total,
+				}
+
+				// A failed IP keeps its lease until it expires, as in the worker.
+				if err != nil {
+					logRefreshError(logrus.WithFields(fields), err)
+					switch {
+					case errors.Is(err, senderscore.ErrNotFound), errors.Is(err, context.Canceled):
+						atomic.AddInt64(&summary.Skipped, 1)
+					case errors.Is(err, senderscore.ErrBlocked):
+						atomic.AddInt64(&summary.Failed, 1)
+						cancel()
+					default:
+						atomic.AddInt64(&summary.Failed, 1)
+					}
+					continue
+				}
+
+				atomic.AddInt64(&summary.Refreshed, 1)
+				logrus.WithFields(fields).Info("IP refreshed")
+
+				if err := ipUC.ReleaseIP(ctx, ip.ID); err != nil {
+					logrus.WithError(err).WithField("ip", ip.IP).Warn("Failed to release IP claim")
+				}
+			}
+		}()
+	}
+
+	for _, ip := range ips {
+		jobs <- ip
+	}
+	close(jobs)
+	wg.Wait()
+
+	return summary
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/data"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure"
+	"git.emercury.dev/emercury/senderscore/api/internal/usecase"
+	"git.emercury.dev/emercury/senderscore/api/pkg/config"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	eventsIP    string
+	eventsField string
+)
+
+func init() {
+	eventsCmd.Flags().StringVarP(&eventsIP, "ip", "i", "", "IP address to show events for (required)")
+	eventsCmd.Flags().StringVarP(&eventsField, "field", "f", "", "Only show changes of this field (score, spam_trap, blocklists, complaints)")
+	eventsCmd.MarkFlagRequired("ip")
+}
+
+var eventsCmd = cobra.Command{
+	Use:   "events",
+	Short: "Show the change timeline of an IP",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		cfg := config.Init(ctx)
+
+		db, err := infrastructure.NewDatabase(cfg.DB.DSN)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to connect to database")
+		}
+
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+
+		ipUC := usecase.NewIPUseCase(
+			data.NewGroupRepository(db, groupScoreThresholds(cfg)),
+			data.NewIPRepository(db),
+			data.NewHistoryRepository(db),
+			data.NewScoreStatRepository(db),
+			data.NewIPEventRepository(db),
+			data.NewUnitOfWork(db, groupScoreThresholds(cfg)),
+			nil,
+		)
+
+		events, err := ipUC.ListEvents(ctx, eventsIP, eventsField)
+		if err != nil {
+			logrus.WithError(err).WithField("ip", eventsIP).Fatal("Failed to list IP events")
+		}
+
+		displayEvents(eventsIP, events)
+	},
+}
+
+func displayEvents(ip string, events []*usecase.IPEventDTO) {
+	purple := lipgloss.Color("#7D56F4")
+	baseStyle := lipgloss.NewStyle().Padding(0, 1)
+
+	eventsTable := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(purple)).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			return baseStyle
+		}).
+		Headers("TIME", "FIELD", "OLD", "NEW")
+
+	for _, event := range events {
+		oldValue := event.OldValue
+		if oldValue == "" {
+			oldValue = "-"
+		}
+		eventsTable.Row(
+			time.Unix(event.CreatedAt, 0).Format("02.01.2006 15:04:05"),
+			event.Field,
+			oldValue,
+			event.NewValue,
+		)
+	}
+
+	ui := lipgloss.JoinVertical(
+		lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(purple).Margin(1, 0).Render(fmt.Sprintf("📜 Events: %s (%d)", ip, len(events))),
+		eventsTable.String(),
+	)
+
+	fmt.Println(ui)
+}
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/data"
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure"
+	"git.emercury.dev/emercury/senderscore/api/internal/usecase"
+	"git.emercury.dev/emercury/senderscore/api/pkg/config"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportGroupID int
+	exportFormat  string
+	exportHistory bool
+	exportOutput  string
+)
+
+func init() {
+	exportCmd.Flags().IntVarP(&exportGroupID, "group", "g", 0, "Group ID to export (default: all groups)")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "json", "Output format: csv or json")
+	exportCmd.Flags().BoolVar(&exportHistory, "history", false, "Include the daily history of every IP")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write to this file instead of stdout")
+}
+
+type exportGroup struct {
+	GroupID            int        `json:"group_id"`
+	GroupName          string     `json:"group_name"`
+	IPsCount           int        `json:"ips_count"`
+	SpamTrapCount      int        `json:"spam_trap_count"`
+	AvgScore           float64    `json:"avg_score"`
+	MinScore           int        `json:"min_score"`
+	WeightedScore      float64    `json:"weighted_score"`
+	LowScoreCount      int        `json:"low_score_count"`
+	CriticalScoreCount int        `json:"critical_score_count"`
+	BlocklistedCount   int        `json:"blocklisted_count"`
+	AvgComplaintRate   float64    `json:"avg_complaint_rate"`
+	IPs                []exportIP `json:"ips"`
+}
+
+type exportIP struct {
+	IP             string               `json:"ip"`
+	Score          int                  `json:"score"`
+	SpamTrap       int                  `json:"spam_trap"`
+	Blocklists     string               `json:"blocklists"`
+	Complaints     string               `json:"complaints"`
+	BlocklistCount int                  `json:"blocklist_count"`
+	ComplaintRate  float64              `json:"complaint_rate"`
+	Status         string               `json:"status"`
+	UpdatedAt      string               `json:"updated_at"`
+	History        []exportHistoryEntry `json:"history,omitempty"`
+}
+
+type exportHistoryEntry struct {
+	Date     string `json:"date"`
+	Score    int    `json:"score"`
+	Volume   int    `json:"volume"`
+	SpamTrap int    `json:"spam_trap"`
+}
+
+var exportCmd = cobra.Command{
+	Use:   "export",
+	Short: "Export groups with their IPs and scores to CSV or JSON",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		cfg := config.Init(ctx)
+
+		if exportFormat != "csv" && exportFormat != "json" {
+			logrus.Fatalf("Unknown export format %q, expected csv or json", exportFormat)
+		}
+
+		db, err := infrastructure.NewDatabase(cfg.DB.DSN)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to connect to database")
+		}
+
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+
+		groupRepo := data.NewGroupRepository(db, groupScoreThresholds(cfg))
+		ipRepo := data.NewIPRepository(db)
+		historyRepo := data.NewHistoryRepository(db)
+		scoreStatRepo := data.NewScoreStatRepository(db)
+
+		groupUC := usecase.NewGroupUseCase(groupRepo, ipRepo, historyRepo, scoreStatRepo, data.NewNotificationChannelRepository(db))
+		ipUC := usecase.NewIPUseCase(groupRepo, ipRepo, historyRepo, scoreStatRepo, data.NewIPEventRepository(db), data.NewUnitOfWork(db, groupScoreThresholds(cfg)), nil)
+
+		groups, err := loadExportGroups(ctx, groupUC, ipUC, exportGroupID, exportHistory)
+		if err != nil {
+			if err == domain.ErrGroupNotFound {
+				logrus.WithField("group_id", exportGroupID).Fatal("Group not found")
+			}
+			logrus.WithError(err).Fatal("Failed to load groups")
+		}
+
+		out := io.Writer(os.Stdout)
+		if exportOutput != "" {
+			file, err := os.Create(exportOutput)
+			if err != nil {
+				logrus.WithError(err).Fatal("Failed to create output file")
+			}
+			defer file.Close()
+			out = file
+		}
+
+		if exportFormat == "csv" {
+			err = writeExportCSV(out, groups, exportHistory)
+		} else {
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(groups)
+		}
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to write export")
+		}
+
+		logrus.WithField("groups", len(groups)).Info("Export completed")
+	},
+}
+
+func loadExportGroups(ctx context.Context, groupUC usecase.GroupUseCase, ipUC usecase.IPUseCase, groupID int, withHistory bool) ([]exportGroup, error) {
+	var groups []*usecase.GroupDTO
+	if groupID != 0 {
+		group, err := groupUC.GetGroupByGroupID(ctx, groupID, true)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	} else {
+		pagination := usecase.PaginationDTO{Page: 1, PageSize: 100}
+		for {
+			page, total, err := groupUC.ListGroups(ctx, usecase.GroupFilterDTO{}, pagination, true)
+			if err != nil {
+				return nil, err
+			}
+			groups = append(groups, page...)
+			if len(page) == 0 || int64(len(groups)) >= total {
+				break
+			}
+			pagination.Page++
+		}
+	}
+
+	result := make([]exportGroup, len(groups))
+	for i, group := range groups {
+		result[i] = exportGroup{
+			GroupID:            group.GroupID,
+			GroupName:          group.GroupName,
+			IPsCount:           group.IPsCount,
+			SpamTrapCount:      group.SpamTrapCount,
+			AvgScore:           group.AvgScore,
+			MinScore:           group.MinScore,
+			WeightedScore:      group.WeightedScore,
+			LowScoreCount:      group.LowScoreCount,
+			CriticalScoreCount: group.CriticalScoreCount,
+			BlocklistedCount:   group.BlocklistedCount,
+			AvgComplaintRate:   group.AvgComplaintRate,
+			IPs:                make([]exportIP, len(group.IPs)),
+		}
+
+		for j, ip := range group.IPs {
+			entry := exportIP{
+				IP:             ip.IP,
+				Score:          ip.Score,
+				SpamTrap:       ip.SpamTrap,
+				Blocklists:     ip.Blocklists,
+				Complaints:     ip.Complaints,
+				BlocklistCount: ip.BlocklistCount,
+				ComplaintRate:  ip.ComplaintRate,
+				Status:         ip.Status,
+				UpdatedAt:      time.Unix(ip.UpdatedAt, 0).Format("02.01.2006 15:04:05"),
+			}
+
+			if withHistory {
+				history, err := ipUC.GetHistory(ctx, ip.IP, time.Time{}, time.Time{})
+				if err != nil {
+					return nil, fmt.Errorf("failed to load history of %s: %w", ip.IP, err)
+				}
+				for _, h := range history {
+					entry.History = append(entry.History, exportHistoryEntry{
+						Date:     h.Date,
+						Score:    h.Score,
+						Volume:   h.Volume,
+						SpamTrap: h.SpamTrap,
+					})
+				}
+			}
+
+			result[i].IPs[j] = entry
+		}
+	}
+
+	return result, nil
+}
+
+// writeExportCSV writes one row per IP, or one row per history day of every
+// IP when withHistory is set. A group without IPs gets a single row with an
+// empty ip column. The group_id, group_name and ip columns make the file
+// importable with the import command.
+func writeExportCSV(w io.Writer, groups []exportGroup, withHistory bool) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{
+		"group_id", "group_name", "ip", "score", "spam_trap", "blocklists", "complaints",
+		"blocklist_count", "complaint_rate", "status", "updated_at",
+	}
+	if withHistory {
+		header = append(header, "date", "history_score", "history_volume", "history_spam_trap")
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		if len(group.IPs) == 0 {
+			row := make([]string, len(header))
+			row[0] = strconv.Itoa(group.GroupID)
+			row[1] = group.GroupName
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, ip := range group.IPs {
+			row := []string{
+				strconv.Itoa(group.GroupID),
+				group.GroupName,
+				ip.IP,
+				strconv.Itoa(ip.Score),
+				strconv.Itoa(ip.SpamTrap),
+				ip.Blocklists,
+				ip.Complaints,
+				strconv.Itoa(ip.BlocklistCount),
+				strconv.FormatFloat(ip.ComplaintRate, 'f', -1, 64),
+				ip.Status,
+				ip.UpdatedAt,
+			}
+
+			if !withHistory {
+				if err := writer.Write(row); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if len(ip.History) == 0 {
+				if err := writer.Write(append(row, "", "", "", "")); err != nil {
+					return err
+				}
+				continue
+			}
+			for _, h := range ip.History {
+				historyRow := append(append([]string{}, row...),
+					h.Date,
+					strconv.Itoa(h.Score),
+					strconv.Itoa(h.Volume),
+					strconv.Itoa(h.SpamTrap),
+				)
+				if err := writer.Write(historyRow); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
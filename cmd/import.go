@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/data"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure"
+	"git.emercury.dev/emercury/senderscore/api/internal/usecase"
+	"git.emercury.dev/emercury/senderscore/api/pkg/config"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFile   string
+	importFormat string
+)
+
+func init() {
+	importCmd.Flags().StringVarP(&importFile, "file", "f", "", "CSV or JSON file to import (required)")
+	importCmd.Flags().StringVar(&importFormat, "format", "", "File format: csv or json (default: by file extension)")
+	importCmd.MarkFlagRequired("file")
+}
+
+var importCmd = cobra.Command{
+	Use:   "import",
+	Short: "Import groups and IPs from a CSV or JSON file",
+	Long: `Adds the IPs of a file to their groups, creating missing groups.
+
+CSV files need a header with the columns group_id, group_name and ip; other
+columns are ignored, so files written by "export --format csv" can be imported
+as is; a row with an empty ip only creates the group. JSON files use the format
+written by "export --format json". In both formats an ip value may also be a
+CIDR range. The whole file is imported in one transaction.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		cfg := config.Init(ctx)
+
+		format := importFormat
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(importFile)), ".")
+		}
+
+		file, err := os.Open(importFile)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to open import file")
+		}
+		defer file.Close()
+
+		var items []usecase.AddIPDTO
+		var emptyGroups []usecase.CreateGroupDTO
+		switch format {
+		case "csv":
+			items, emptyGroups, err = readImportCSV(file)
+		case "json":
+			items, emptyGroups, err = readImportJSON(file)
+		default:
+			logrus.Fatalf("Unknown import format %q, expected csv or json", format)
+		}
+		if err != nil {
+			logrus.WithError(err).WithField("file", importFile).Fatal("Failed to read import file")
+		}
+		if len(items) == 0 && len(emptyGroups) == 0 {
+			logrus.WithField("file", importFile).Warn("Nothing to import")
+			return
+		}
+
+		db, err := infrastructure.NewDatabase(cfg.DB.DSN)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to connect to database")
+		}
+
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+
+		groupRepo := data.NewGroupRepository(db, groupScoreThresholds(cfg))
+		ipRepo := data.NewIPRepository(db)
+		historyRepo := data.NewHistoryRepository(db)
+		scoreStatRepo := data.NewScoreStatRepository(db)
+
+		ipUC := usecase.NewIPUseCase(
+			groupRepo,
+			ipRepo,
+			historyRepo,
+			scoreStatRepo,
+			data.NewIPEventRepository(db),
+			data.NewUnitOfWork(db, groupScoreThresholds(cfg)),
+			nil,
+		)
+
+		result, err := ipUC.ImportIPs(ctx, items, emptyGroups)
+		if result != nil {
+			for _, item := range result.Items {
+				if item.Status != usecase.AddIPStatusInvalid && item.Status != usecase.AddIPStatusFailed {
+					continue
+				}
+				logrus.WithFields(logrus.Fields{
+					"item":     item.Index + 1,
+					"ip":       item.IP,
+					"group_id": item.GroupID,
+					"status":   item.Status,
+				}).Warn(item.Reason)
+			}
+		}
+		if err != nil {
+			logrus.WithError(err).Fatal("Import failed, nothing was saved")
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"groups_created": result.GroupsCreated,
+			"ips_created":    result.IPsCreated,
+			"ips_linked":     result.IPsSkipped,
+			"ips_invalid":    result.IPsInvalid,
+		}).Info("Import completed")
+	},
+}
+
+// readImportCSV returns the IPs of the file and the groups that are listed
+// only by rows with an empty ip.
+func readImportCSV(r io.Reader) ([]usecase.AddIPDTO, []usecase.CreateGroupDTO, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"group_id", "ip"} {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var items []usecase.AddIPDTO
+	var groups []usecase.CreateGroupDTO
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		groupID, err := strconv.Atoi(field(record, "group_id"))
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: invalid group_id %q", line, field(record, "group_id"))
+		}
+
+		address := field(record, "ip")
+		if address == "" {
+			groups = append(groups, usecase.CreateGroupDTO{
+				GroupID:   groupID,
+				GroupName: field(record, "group_name"),
+			})
+			continue
+		}
+
+		items = append(items, importItem(groupID, field(record, "group_name"), address))
+	}
+
+	return items, groups, nil
+}
+
+func readImportJSON(r io.Reader) ([]usecase.AddIPDTO, []usecase.CreateGroupDTO, error) {
+	var groups []exportGroup
+	if err := json.NewDecoder(r).Decode(&groups); err != nil {
+		return nil, nil, err
+	}
+
+	var items []usecase.AddIPDTO
+	var emptyGroups []usecase.CreateGroupDTO
+	for _, group := range groups {
+		if len(group.IPs) == 0 {
+			emptyGroups = append(emptyGroups, usecase.CreateGroupDTO{
+				GroupID:   group.GroupID,
+				GroupName: group.GroupName,
+			})
+			continue
+		}
+		for _, ip := range group.IPs {
+			items = append(items, importItem(group.GroupID, group.GroupName, ip.IP))
+		}
+	}
+
+	return items, emptyGroups, nil
+}
+
+// importItem builds the item for an address of the file; a value with a
+// prefix length is imported as a CIDR range.
+func importItem(groupID int, groupName, address string) usecase.AddIPDTO {
+	item := usecase.AddIPDTO{GroupID: groupID, GroupName: groupName}
+	if strings.Contains(address, "/") {
+		item.CIDR = address
+	} else {
+		item.IP = address
+	}
+	return item
+}
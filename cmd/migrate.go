@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"git.emercury.dev/emercury/senderscore/api/internal/data"
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
 	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure"
 	"git.emercury.dev/emercury/senderscore/api/pkg/config"
 	"github.com/go-gormigrate/gormigrate/v2"
@@ -53,6 +58,306 @@ var migrateCmd = cobra.Command{
 					)
 				},
 			},
+			{
+				ID: "202610171200_add_ip_claimed_until",
+				Migrate: func(tx *gorm.DB) error {
+					if !tx.Migrator().HasColumn(&data.IPModel{}, "ClaimedUntil") {
+						if err := tx.Migrator().AddColumn(&data.IPModel{}, "ClaimedUntil"); err != nil {
+							return err
+						}
+					}
+					if !tx.Migrator().HasIndex(&data.IPModel{}, "idx_ips_claimed") {
+						if err := tx.Migrator().CreateIndex(&data.IPModel{}, "idx_ips_claimed"); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+				Rollback: func(tx *gorm.DB) error {
+					return tx.Migrator().DropColumn(&data.IPModel{}, "ClaimedUntil")
+				},
+			},
+			{
+				ID: "202610171300_add_ip_status",
+				Migrate: func(tx *gorm.DB) error {
+					if tx.Migrator().HasColumn(&data.IPModel{}, "Status") {
+						return nil
+					}
+					return tx.Migrator().AddColumn(&data.IPModel{}, "Status")
+				},
+				Rollback: func(tx *gorm.DB) error {
+					return tx.Migrator().DropColumn(&data.IPModel{}, "Status")
+				},
+			},
+			{
+				ID: "202610171400_create_raw_reports",
+				Migrate: func(tx *gorm.DB) error {
+					return tx.AutoMigrate(&data.RawReportModel{})
+				},
+				Rollback: func(tx *gorm.DB) error {
+					return tx.Migrator().DropTable("sender_score_raw_reports")
+				},
+			},
+			{
+				ID: "202610171500_add_group_score_metrics",
+				Migrate: func(tx *gorm.DB) error {
+					for _, field := range groupScoreMetricFields {
+						if tx.Migrator().HasColumn(&data.GroupModel{}, field) {
+							continue
+						}
+						if err := tx.Migrator().AddColumn(&data.GroupModel{}, field); err != nil {
+							return err
+						}
+					}
+
+					// Пересчет метрик для уже существующих групп. SQL зафиксирован на момент
+					// миграции и использует только колонки, существующие на этом шаге;
+					// blocklisted_count заполняет 202610171900_add_ip_reputation_numbers
+					thresholds := groupScoreThresholds(cfg)
+					if err := tx.Exec(`UPDATE sender_score_groups g SET
+						avg_score = s.avg_score,
+						min_score = s.min_score,
+						low_score_count = s.low_score_count,
+						critical_score_count = s.critical_score_count
+					FROM (
+						SELECT gi.group_id,
+							COALESCE(AVG(ips.score), 0) AS avg_score,
+							COALESCE(MIN(ips.score), 0) AS min_score,
+							COUNT(*) FILTER (WHERE ips.score < ?) AS low_score_count,
+							COUNT(*) FILTER (WHERE ips.score < ?) AS critical_score_count
+						FROM sender_score_group_ips gi
+						JOIN sender_score_ips ips ON ips.id = gi.ip_id
+						WHERE ips.status = 'ok'
+						GROUP BY gi.group_id
+					) s
+					WHERE g.id = s.group_id`, thresholds.Low, thresholds.Critical).Error; err != nil {
+						return err
+					}
+
+					// Вес IP - объем из его последней записи истории
+					return tx.Exec(`UPDATE sender_score_groups g SET
+						weighted_score = s.weighted_score
+					FROM (
+						SELECT gi.group_id,
+							COALESCE(SUM(ips.score * latest.volume)::float / NULLIF(SUM(latest.volume), 0), 0) AS weighted_score
+						FROM sender_score_group_ips gi
+						JOIN sender_score_ips ips ON ips.id = gi.ip_id
+						JOIN (
+							SELECT DISTINCT ON (ips_id) ips_id, volume
+							FROM sender_score_histories
+							ORDER BY ips_id, time DESC
+						) latest ON latest.ips_id = ips.id
+						WHERE ips.status = 'ok'
+						GROUP BY gi.group_id
+					) s
+					WHERE g.id = s.group_id`).Error
+				},
+				Rollback: func(tx *gorm.DB) error {
+					for _, field := range groupScoreMetricFields {
+						if err := tx.Migrator().DropColumn(&data.GroupModel{}, field); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			},
+			{
+				ID: "202610171600_create_alert_states",
+				Migrate: func(tx *gorm.DB) error {
+					if err := tx.AutoMigrate(&alertStateV1{}); err != nil {
+						return err
+					}
+					return addCascadeForeignKey(tx, "sender_score_alert_states", "fk_sender_score_alert_states_ip_record", "ips_id", "sender_score_ips")
+				},
+				Rollback: func(tx *gorm.DB) error {
+					return tx.Migrator().DropTable("sender_score_alert_states")
+				},
+			},
+			{
+				ID: "202610171700_create_notification_channels",
+				Migrate: func(tx *gorm.DB) error {
+					if err := tx.AutoMigrate(&notificationChannelV1{}); err != nil {
+						return err
+					}
+					return addCascadeForeignKey(tx, "sender_score_notification_channels", "fk_sender_score_notification_channels_group", "group_id", "sender_score_groups")
+				},
+				Rollback: func(tx *gorm.DB) error {
+					return tx.Migrator().DropTable("sender_score_notification_channels")
+				},
+			},
+			{
+				ID: "202610171800_create_ip_events",
+				Migrate: func(tx *gorm.DB) error {
+					if err := tx.AutoMigrate(&ipEventV1{}); err != nil {
+						return err
+					}
+					return addCascadeForeignKey(tx, "sender_score_ip_events", "fk_sender_score_ip_events_ip_record", "ips_id", "sender_score_ips")
+				},
+				Rollback: func(tx *gorm.DB) error {
+					return tx.Migrator().DropTable("sender_score_ip_events")
+				},
+			},
+			{
+				ID: "202610171900_add_ip_reputation_numbers",
+				Migrate: func(tx *gorm.DB) error {
+					for _, field := range []string{"BlocklistCount", "ComplaintRate"} {
+						if tx.Migrator().HasColumn(&data.IPModel{}, field) {
+							continue
+						}
+						if err := tx.Migrator().AddColumn(&data.IPModel{}, field); err != nil {
+							return err
+						}
+					}
+					if !tx.Migrator().HasIndex(&data.IPModel{}, "idx_ips_blocklist_count") {
+						if err := tx.Migrator().CreateIndex(&data.IPModel{}, "idx_ips_blocklist_count"); err != nil {
+							return err
+						}
+					}
+					if !tx.Migrator().HasColumn(&data.GroupModel{}, "AvgComplaintRate") {
+						if err := tx.Migrator().AddColumn(&data.GroupModel{}, "AvgComplaintRate"); err != nil {
+							return err
+						}
+					}
+
+					// Заполнение числовых полей из исходного текста отчетов. SQL повторяет
+					// domain.BlocklistCountOf и domain.ParseComplaintRate на момент миграции
+					if err := tx.Exec(`UPDATE sender_score_ips SET
+						blocklist_count = CASE
+							WHEN btrim(COALESCE(blocklists, '')) = '' THEN 0
+							WHEN btrim(blocklists) ~ '^[0-9]+' THEN substring(btrim(blocklists) FROM '^[0-9]+')::int
+							ELSE -1
+						END,
+						complaint_rate = CASE
+							WHEN btrim(COALESCE(complaints, '')) ~ '^[0-9]+(?:\.[0-9]+)?' THEN substring(btrim(complaints) FROM '^[0-9]+(?:\.[0-9]+)?')::double precision
+							ELSE 0
+						END`).Error; err != nil {
+						return err
+					}
+
+					// Пересчет blocklisted_count и avg_complaint_rate групп по IP со статусом ok
+					if err := tx.Exec(`UPDATE sender_score_groups SET blocklisted_count = 0, avg_complaint_rate = 0`).Error; err != nil {
+						return err
+					}
+					return tx.Exec(`UPDATE sender_score_groups g SET
+						blocklisted_count = s.blocklisted_count,
+						avg_complaint_rate = s.avg_complaint_rate
+					FROM (
+						SELECT gi.group_id,
+							COUNT(*) FILTER (WHERE ips.blocklist_count <> 0) AS blocklisted_count,
+							COALESCE(AVG(ips.complaint_rate), 0) AS avg_complaint_rate
+						FROM sender_score_group_ips gi
+						JOIN sender_score_ips ips ON ips.id = gi.ip_id
+						WHERE ips.status = 'ok'
+						GROUP BY gi.group_id
+					) s
+					WHERE g.id = s.group_id`).Error
+				},
+				Rollback: func(tx *gorm.DB) error {
+					if err := tx.Migrator().DropColumn(&data.GroupModel{}, "AvgComplaintRate"); err != nil {
+						return err
+					}
+					for _, field := range []string{"BlocklistCount", "ComplaintRate"} {
+						if err := tx.Migrator().DropColumn(&data.IPModel{}, field); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			},
+			{
+				ID: "202610172000_convert_ip_to_inet",
+				Migrate: func(tx *gorm.DB) error {
+					// Адреса приводятся к канонической форме до смены типа колонки,
+					// чтобы одна и та же запись не превратилась в два разных inet
+					var models []data.IPModel
+					if err := tx.Select("id", "ip").Find(&models).Error; err != nil {
+						return err
+					}
+
+					var invalid []string
+					for _, model := range models {
+						address := strings.TrimSpace(model.IP)
+						canonical, err := domain.CanonicalIP(address)
+						if err != nil {
+							invalid = append(invalid, fmt.Sprintf("%d:%q", model.ID, address))
+							continue
+						}
+						if canonical == model.IP {
+							continue
+						}
+						if err := tx.Model(&data.IPModel{}).Where("id = ?", model.ID).Update("ip", canonical).Error; err != nil {
+							return err
+						}
+					}
+					if len(invalid) > 0 {
+						return fmt.Errorf("invalid IP addresses must be fixed or removed first: %s", strings.Join(invalid, ", "))
+					}
+
+					return tx.Exec("ALTER TABLE sender_score_ips ALTER COLUMN ip TYPE inet USING trim(ip::text)::inet").Error
+				},
+				Rollback: func(tx *gorm.DB) error {
+					return tx.Exec("ALTER TABLE sender_score_ips ALTER COLUMN ip TYPE varchar(45) USING host(ip)").Error
+				},
+			},
+			{
+				ID: "202610172100_add_history_ip_time_unique",
+				Migrate: func(tx *gorm.DB) error {
+					// Из дублей за один день остается последняя запись
+					if err := tx.Exec(`DELETE FROM sender_score_histories a
+						USING sender_score_histories b
+						WHERE a.ips_id = b.ips_id AND a.time = b.time AND a.id < b.id`).Error; err != nil {
+						return err
+					}
+					if tx.Migrator().HasIndex(&data.HistoryModel{}, "idx_hist_ip_time") {
+						return nil
+					}
+					return tx.Migrator().CreateIndex(&data.HistoryModel{}, "idx_hist_ip_time")
+				},
+				Rollback: func(tx *gorm.DB) error {
+					return tx.Migrator().DropIndex(&data.HistoryModel{}, "idx_hist_ip_time")
+				},
+			},
+			{
+				ID: "202610172200_add_alert_state_delivery",
+				Migrate: func(tx *gorm.DB) error {
+					for _, field := range []string{"Delivered", "Completed"} {
+						if tx.Migrator().HasColumn(&data.AlertStateModel{}, field) {
+							continue
+						}
+						if err := tx.Migrator().AddColumn(&data.AlertStateModel{}, field); err != nil {
+							return err
+						}
+					}
+					// Раньше состояние оставалось активным только после успешной
+					// отправки, поэтому существующие алерты считаются доставленными
+					return tx.Exec("UPDATE sender_score_alert_states SET completed = true").Error
+				},
+				Rollback: func(tx *gorm.DB) error {
+					for _, field := range []string{"Delivered", "Completed"} {
+						if err := tx.Migrator().DropColumn(&data.AlertStateModel{}, field); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			},
+			{
+				ID: "202610172300_add_score_stat_observed_at",
+				Migrate: func(tx *gorm.DB) error {
+					if !tx.Migrator().HasColumn(&data.ScoreStatModel{}, "ObservedAt") {
+						if err := tx.Migrator().AddColumn(&data.ScoreStatModel{}, "ObservedAt"); err != nil {
+							return err
+						}
+					}
+					if tx.Migrator().HasIndex(&data.ScoreStatModel{}, "idx_stats_ip_observed") {
+						return nil
+					}
+					return tx.Migrator().CreateIndex(&data.ScoreStatModel{}, "idx_stats_ip_observed")
+				},
+				Rollback: func(tx *gorm.DB) error {
+					return tx.Migrator().DropColumn(&data.ScoreStatModel{}, "ObservedAt")
+				},
+			},
 		})
 
 		if err := m.Migrate(); err != nil {
@@ -61,3 +366,64 @@ var migrateCmd = cobra.Command{
 		logrus.Info("Migration run successfully")
 	},
 }
+
+var groupScoreMetricFields = []string{
+	"AvgScore",
+	"MinScore",
+	"WeightedScore",
+	"LowScoreCount",
+	"CriticalScoreCount",
+	"BlocklistedCount",
+}
+
+// Таблицы, созданные после базовой миграции, описываются структурами на момент
+// своей миграции. AutoMigrate живой модели со связью мигрирует и связанную
+// модель (IPModel, GroupModel) и применил бы их более поздние изменения раньше
+// соответствующих миграций.
+
+type alertStateV1 struct {
+	ID          uint      `gorm:"primaryKey;comment:ID"`
+	IpsID       uint      `gorm:"not null;uniqueIndex:idx_alert_state;comment:IPs ID"`
+	Rule        string    `gorm:"type:varchar(32);uniqueIndex:idx_alert_state;comment:Rule"`
+	TriggeredAt time.Time `gorm:"comment:Triggered At"`
+}
+
+func (alertStateV1) TableName() string {
+	return "sender_score_alert_states"
+}
+
+type notificationChannelV1 struct {
+	ID      uint   `gorm:"primaryKey;comment:ID"`
+	GroupID uint   `gorm:"not null;uniqueIndex:idx_channel_target;comment:Group Internal ID"`
+	Type    string `gorm:"type:varchar(16);uniqueIndex:idx_channel_target;comment:Type"`
+	Target  string `gorm:"type:varchar(512);uniqueIndex:idx_channel_target;comment:Target"`
+}
+
+func (notificationChannelV1) TableName() string {
+	return "sender_score_notification_channels"
+}
+
+type ipEventV1 struct {
+	ID        uint      `gorm:"primaryKey;comment:ID"`
+	IpsID     uint      `gorm:"not null;index:idx_ip_events_ip_created;comment:IPs ID"`
+	Field     string    `gorm:"type:varchar(32);comment:Field"`
+	OldValue  string    `gorm:"type:varchar(50);comment:Old Value"`
+	NewValue  string    `gorm:"type:varchar(50);comment:New Value"`
+	CreatedAt time.Time `gorm:"index:idx_ip_events_ip_created;comment:Created"`
+}
+
+func (ipEventV1) TableName() string {
+	return "sender_score_ip_events"
+}
+
+// addCascadeForeignKey создает внешний ключ, который gorm создал бы для связи
+// belongs-to, не трогая таблицу, на которую он ссылается.
+func addCascadeForeignKey(tx *gorm.DB, table, name, column, refTable string) error {
+	if tx.Migrator().HasConstraint(table, name) {
+		return nil
+	}
+	return tx.Exec(fmt.Sprintf(
+		"ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s(id) ON UPDATE CASCADE ON DELETE CASCADE",
+		table, name, column, refTable,
+	)).Error
+}
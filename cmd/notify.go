@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure"
+	"git.emercury.dev/emercury/senderscore/api/pkg/config"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	notifyTestGroupID int
+	notifyTestIP      string
+)
+
+func init() {
+	notifyTestCmd.Flags().IntVarP(&notifyTestGroupID, "group", "g", 0, "Group whose notification channels receive the test alert (required)")
+	notifyTestCmd.Flags().StringVarP(&notifyTestIP, "ip", "i", "192.0.2.1", "IP address to put into the test alert")
+	notifyTestCmd.MarkFlagRequired("group")
+}
+
+var notifyTestCmd = cobra.Command{
+	Use:   "notify-test",
+	Short: "Send a test alert through the configured notification channels",
+	Long:  "Sends a test alert to the configured webhooks and to the chat and email channels of the group, bypassing alert rules and deduplication",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		cfg := config.Init(ctx)
+
+		db, err := infrastructure.NewDatabase(cfg.DB.DSN)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to connect to database")
+		}
+
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+
+		notifier := newAlertNotifier(cfg, db)
+		if notifier == nil {
+			logrus.Fatal("No notification channels are enabled, set ALERT_WEBHOOK_URLS or ALERT_GROUP_CHANNELS")
+		}
+
+		alert := &domain.Alert{
+			Rule:        "test",
+			IP:          notifyTestIP,
+			GroupIDs:    []int{notifyTestGroupID},
+			Score:       65,
+			Message:     "Test alert, please ignore",
+			TriggeredAt: time.Now(),
+		}
+
+		if err := notifier.Notify(ctx, alert, make(map[string]bool)); err != nil {
+			logrus.WithError(err).Fatal("Failed to send test alert")
+		}
+
+		logrus.WithField("group_id", notifyTestGroupID).Info("Test alert sent")
+	},
+}
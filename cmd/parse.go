@@ -7,8 +7,8 @@ import (
 	"time"
 
 	"git.emercury.dev/emercury/senderscore/api/internal/data"
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
 	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure"
-	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure/senderscore"
 	"git.emercury.dev/emercury/senderscore/api/internal/usecase"
 	"git.emercury.dev/emercury/senderscore/api/pkg/config"
 	"github.com/charmbracelet/lipgloss"
@@ -39,11 +39,12 @@ var parseCmd = cobra.Command{
 		sqlDB, _ := db.DB()
 		defer sqlDB.Close()
 
-		groupRepo := data.NewGroupRepository(db)
+		groupRepo := data.NewGroupRepository(db, groupScoreThresholds(cfg))
 		ipRepo := data.NewIPRepository(db)
 		historyRepo := data.NewHistoryRepository(db)
+		scoreStatRepo := data.NewScoreStatRepository(db)
 
-		ipUC := usecase.NewIPUseCase(groupRepo, ipRepo, historyRepo)
+		ipUC := usecase.NewIPUseCase(groupRepo, ipRepo, historyRepo, scoreStatRepo, data.NewIPEventRepository(db), data.NewUnitOfWork(db, groupScoreThresholds(cfg)), newAlerter(cfg, db))
 
 		targetIP := ip
 		var oldestIP *usecase.IPDTO
@@ -63,36 +64,29 @@ var parseCmd = cobra.Command{
 			logrus.WithField("ip", targetIP).Info("Processing specified IP")
 		}
 
-		client := &http.Client{Timeout: 15 * time.Second}
-		req := senderscore.NewRequestWrapper(client)
-		senderClient := senderscore.NewSenderClient(req)
-		report, err := senderClient.GetReport(ip)
+		provider, err := newScoreProvider(cfg, db, &http.Client{Timeout: 15 * time.Second})
 		if err != nil {
-			logrus.WithError(err).Fatal("Failed to get sender score report")
+			logrus.WithError(err).Fatal("Failed to create score provider")
 		}
 
-		parser := infrastructure.NewParser(report)
-		result := parser.Parse()
+		report, err := provider.Fetch(ctx, targetIP)
+		if err != nil {
+			logRefreshError(logrus.WithField("ip", targetIP), err)
+			return
+		}
 
 		logrus.WithFields(logrus.Fields{
 			"ip":          targetIP,
-			"score":       result.SenderScore,
-			"spam_traps":  result.SpamTrap,
-			"blocklists":  result.Blocklists,
-			"complaints":  result.Complaints,
-			"history_cnt": len(result.SSTrend),
+			"source":      report.Source,
+			"no_data":     report.NoData,
+			"score":       report.Score,
+			"spam_traps":  report.SpamTrap,
+			"blocklists":  report.Blocklists,
+			"complaints":  report.Complaints,
+			"history_cnt": len(report.History),
 		}).Info("Parsed sender score data")
 
-		submitDTO := usecase.SubmitScoreDTO{
-			IP:         targetIP,
-			Score:      result.SenderScore,
-			SpamTrap:   result.SpamTrap,
-			Blocklists: result.Blocklists,
-			Complaints: result.Complaints,
-			History:    convertToHistoryDTO(result),
-		}
-
-		submitResult, err := ipUC.SubmitScore(ctx, submitDTO)
+		submitResult, err := ipUC.SubmitScore(ctx, toSubmitScoreDTO(report))
 		if err != nil {
 			logrus.WithError(err).Error("Failed to save to database")
 		} else {
@@ -117,18 +111,13 @@ var parseCmd = cobra.Command{
 			}
 		}
 
-		volumes := make(map[string]int)
-		for _, v := range result.SSVolume {
-			volumes[v.Timestamp] = v.Value
-		}
-
-		displayResults(targetIP, result, volumes)
+		displayResults(targetIP, report)
 
 		logrus.Info("Parse process completed successfully")
 	},
 }
 
-func displayResults(ip string, result *infrastructure.Result, volumes map[string]int) {
+func displayResults(ip string, report *domain.ScoreReport) {
 	purple := lipgloss.Color("#7D56F4")
 	green := lipgloss.Color("#00C853")
 	yellow := lipgloss.Color("#FFD600")
@@ -137,9 +126,9 @@ func displayResults(ip string, result *infrastructure.Result, volumes map[string
 
 	var scoreColor lipgloss.Color
 	switch {
-	case result.SenderScore >= 80:
+	case report.Score >= 80:
 		scoreColor = green
-	case result.SenderScore >= 60:
+	case report.Score >= 60:
 		scoreColor = yellow
 	default:
 		scoreColor = red
@@ -156,10 +145,10 @@ func displayResults(ip string, result *infrastructure.Result, volumes map[string
 		}).
 		Headers("Metric", "Value").
 		Rows(
-			[]string{"Sender Score", strconv.Itoa(result.SenderScore)},
-			[]string{"Spam Traps", strconv.Itoa(result.SpamTrap)},
-			[]string{"Blocklists", result.Blocklists},
-			[]string{"Complaints", result.Complaints},
+			[]string{"Sender Score", strconv.Itoa(report.Score)},
+			[]string{"Spam Traps", strconv.Itoa(report.SpamTrap)},
+			[]string{"Blocklists", report.Blocklists},
+			[]string{"Complaints", report.Complaints},
 		)
 
 	detailTable := table.New().
@@ -170,15 +159,11 @@ func displayResults(ip string, result *infrastructure.Result, volumes map[string
 		}).
 		Headers("DATE", "SCORE", "VOLUME")
 
-	for _, p := range result.SSTrend {
-		ms, _ := strconv.ParseInt(p.Timestamp, 10, 64)
-		tm := time.Unix(0, ms*int64(time.Millisecond))
-		vol := volumes[p.Timestamp]
-
+	for _, p := range report.History {
 		detailTable.Row(
-			tm.Format("02.01.2006"),
-			strconv.Itoa(p.Value),
-			strconv.Itoa(vol),
+			p.Date.Format("02.01.2006"),
+			strconv.Itoa(p.Score),
+			strconv.Itoa(p.Volume),
 		)
 	}
 
@@ -198,7 +183,7 @@ func displayResults(ip string, result *infrastructure.Result, volumes map[string
 		lipgloss.Left,
 		titleStyle.Render(reportTitle),
 		summaryTable.String(),
-		historyTitle.Render(fmt.Sprintf("History (%d days):", len(result.SSTrend))),
+		historyTitle.Render(fmt.Sprintf("History (%d days):", len(report.History))),
 		detailTable.String(),
 	)
 
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/data"
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure/senderscore"
+	"git.emercury.dev/emercury/senderscore/api/pkg/config"
+	"gorm.io/gorm"
+)
+
+func newScoreProvider(cfg *config.Config, db *gorm.DB, httpClient senderscore.HttpClientInterface) (domain.ScoreProvider, error) {
+	switch cfg.Provider.Name {
+	case infrastructure.ProviderSenderScore:
+		archive, err := newReportArchive(cfg, db)
+		if err != nil {
+			return nil, err
+		}
+		req := senderscore.NewRequestWrapper(httpClient)
+		return infrastructure.NewSenderScoreProvider(senderscore.NewSenderClient(req), archive), nil
+	case infrastructure.ProviderFile:
+		if cfg.Provider.Dir == "" {
+			return nil, fmt.Errorf("PROVIDER_DIR is required for the %q provider", infrastructure.ProviderFile)
+		}
+		return infrastructure.NewFileProvider(cfg.Provider.Dir), nil
+	default:
+		return nil, fmt.Errorf("unknown score provider %q", cfg.Provider.Name)
+	}
+}
+
+func newReportArchive(cfg *config.Config, db *gorm.DB) (domain.ReportArchive, error) {
+	switch cfg.Archive.Backend {
+	case infrastructure.ArchiveNone:
+		return nil, nil
+	case infrastructure.ArchiveDB:
+		if db == nil {
+			return nil, fmt.Errorf("the %q archive requires a database connection", infrastructure.ArchiveDB)
+		}
+		return data.NewRawReportRepository(db), nil
+	case infrastructure.ArchiveDir:
+		if cfg.Archive.Dir == "" {
+			return nil, fmt.Errorf("ARCHIVE_DIR is required for the %q archive", infrastructure.ArchiveDir)
+		}
+		return infrastructure.NewDirArchive(cfg.Archive.Dir), nil
+	default:
+		return nil, fmt.Errorf("unknown report archive %q", cfg.Archive.Backend)
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure/senderscore"
+	"git.emercury.dev/emercury/senderscore/api/internal/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+func refreshIP(
+	ctx context.Context,
+	ipUC usecase.IPUseCase,
+	provider domain.ScoreProvider,
+	ip string,
+	fetchTimeout time.Duration,
+) (*usecase.SubmitScoreResultDTO, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	report, err := provider.Fetch(fetchCtx, ip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch report from %s: %w", provider.Name(), err)
+	}
+
+	if report.NoData {
+		logrus.WithField("ip", ip).Info("Score provider has no data for IP")
+	}
+	if len(report.MissingFields) > 0 {
+		logrus.WithFields(logrus.Fields{
+			"ip":             ip,
+			"missing_fields": report.MissingFields,
+		}).Warn("Report is missing optional fields")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"ip":          ip,
+		"source":      report.Source,
+		"no_data":     report.NoData,
+		"score":       report.Score,
+		"spam_traps":  report.SpamTrap,
+		"blocklists":  report.Blocklists,
+		"complaints":  report.Complaints,
+		"history_cnt": len(report.History),
+	}).Info("Parsed sender score data")
+
+	submitResult, err := ipUC.SubmitScore(ctx, toSubmitScoreDTO(report))
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit score: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"ip":              ip,
+		"ip_created":      submitResult.IPCreated,
+		"history_added":   submitResult.HistoryAdded,
+		"history_updated": submitResult.HistoryUpdated,
+	}).Info("Successfully updated IP score")
+
+	return submitResult, nil
+}
+
+func toSubmitScoreDTO(report *domain.ScoreReport) usecase.SubmitScoreDTO {
+	if report.NoData {
+		return usecase.SubmitScoreDTO{IP: report.IP, NoData: true, ObservedAt: report.FetchedAt}
+	}
+
+	return usecase.SubmitScoreDTO{
+		IP:         report.IP,
+		ObservedAt: report.FetchedAt,
+		Score:      report.Score,
+		SpamTrap:   report.SpamTrap,
+		Blocklists: report.Blocklists,
+		Complaints: report.Complaints,
+		History:    toHistoryEntryDTOs(report.History),
+
+		BlocklistCount: report.BlocklistCount,
+		ComplaintRate:  report.ComplaintRate,
+	}
+}
+
+func toHistoryEntryDTOs(points []domain.HistoryPoint) []usecase.HistoryEntryDTO {
+	history := make([]usecase.HistoryEntryDTO, 0, len(points))
+	for _, p := range points {
+		history = append(history, usecase.HistoryEntryDTO{
+			Date:     p.Date.Format("02.01.2006"),
+			Score:    p.Score,
+			Volume:   p.Volume,
+			SpamTrap: p.SpamTrap,
+		})
+	}
+	return history
+}
+
+// logRefreshError logs a failed refresh at a level matching how it should
+// be handled: skipped, retried on a later run, or escalated.
+func logRefreshError(entry *logrus.Entry, err error) {
+	entry = entry.WithError(err)
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		entry.Info("Refresh cancelled")
+	case errors.Is(err, senderscore.ErrNotFound):
+		entry.Warn("No report available for IP, skipping")
+	case errors.Is(err, senderscore.ErrRateLimited),
+		errors.Is(err, senderscore.ErrUpstreamDown),
+		errors.Is(err, context.DeadlineExceeded):
+		entry.Warn("Score provider temporarily unavailable, IP will be retried later")
+	case errors.Is(err, senderscore.ErrBlocked):
+		entry.Error("Requests to senderscore.org are blocked, check egress IP")
+	case errors.Is(err, infrastructure.ErrInvalidReport):
+		var validationErr *infrastructure.ValidationError
+		if errors.As(err, &validationErr) {
+			entry = entry.WithField("issues", validationErr.Issues)
+		}
+		entry.Error("Report failed validation and was not saved")
+	default:
+		entry.Error("Failed to update IP")
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/data"
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure"
+	"git.emercury.dev/emercury/senderscore/api/internal/usecase"
+	"git.emercury.dev/emercury/senderscore/api/pkg/config"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reparseIP    string
+	reparseSince string
+)
+
+func init() {
+	reparseCmd.Flags().StringVarP(&reparseIP, "ip", "i", "", "Only re-parse reports archived for this IP")
+	reparseCmd.Flags().StringVar(&reparseSince, "since", "", "Only re-parse reports fetched on or after this date (DD.MM.YYYY)")
+}
+
+var reparseCmd = cobra.Command{
+	Use:   "reparse",
+	Short: "Re-run the parser over archived report pages",
+	Long:  "Parses archived senderscore.org pages again in the order they were fetched and re-applies them to the database, without contacting senderscore.org",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		cfg := config.Init(ctx)
+
+		filter := domain.RawReportFilter{IP: reparseIP}
+		if reparseSince != "" {
+			since, err := time.ParseInLocation("02.01.2006", reparseSince, time.Local)
+			if err != nil {
+				logrus.WithError(err).Fatal("Invalid --since date, expected DD.MM.YYYY")
+			}
+			filter.Since = since
+		}
+
+		db, err := infrastructure.NewDatabase(cfg.DB.DSN)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to connect to database")
+		}
+
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+
+		archive, err := newReportArchive(cfg, db)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to open report archive")
+		}
+		if archive == nil {
+			logrus.Fatal("Report archive is disabled, set ARCHIVE_BACKEND")
+		}
+
+		groupRepo := data.NewGroupRepository(db, groupScoreThresholds(cfg))
+		ipRepo := data.NewIPRepository(db)
+		historyRepo := data.NewHistoryRepository(db)
+		scoreStatRepo := data.NewScoreStatRepository(db)
+
+		// Повторный разбор старых отчетов не должен рассылать алерты
+		ipUC := usecase.NewIPUseCase(groupRepo, ipRepo, historyRepo, scoreStatRepo, data.NewIPEventRepository(db), data.NewUnitOfWork(db, groupScoreThresholds(cfg)), nil)
+
+		applied, skipped, failed := 0, 0, 0
+		err = archive.Each(ctx, filter, func(raw *domain.RawReport) error {
+			entry := logrus.WithFields(logrus.Fields{
+				"ip":         raw.IP,
+				"fetched_at": raw.FetchedAt.Format("02.01.2006 15:04:05"),
+			})
+
+			// Reports of IPs removed since they were fetched must not
+			// bring them back.
+			if _, err := ipRepo.GetByIP(ctx, raw.IP); err != nil {
+				if errors.Is(err, domain.ErrIPNotFound) {
+					skipped++
+					return nil
+				}
+				return err
+			}
+
+			if err := reparseReport(ctx, ipUC, raw); err != nil {
+				logRefreshError(entry, err)
+				failed++
+				return nil
+			}
+
+			entry.Info("Archived report re-applied")
+			applied++
+			return nil
+		})
+		if err != nil {
+			logrus.WithError(err).Error("Re-parse stopped")
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"applied": applied,
+			"skipped": skipped,
+			"failed":  failed,
+		}).Info("Re-parse completed")
+	},
+}
+
+func reparseReport(ctx context.Context, ipUC usecase.IPUseCase, raw *domain.RawReport) error {
+	report, err := infrastructure.ParseArchivedReport(raw)
+	if err != nil {
+		return err
+	}
+
+	// Статистика, события и updated_at датируются временем получения отчета,
+	// поэтому повторный разбор не дублирует статистику проверок
+	_, err = ipUC.SubmitScore(ctx, toSubmitScoreDTO(report))
+	return err
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"sync"
 
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"git.emercury.dev/emercury/senderscore/api/pkg/config"
 	"github.com/spf13/cobra"
 )
 
@@ -15,6 +17,13 @@ var (
 
 func RootCommand(wg *sync.WaitGroup) *cobra.Command {
 	mainWG = wg
-	rootCmd.AddCommand(&serveCmd, &migrateCmd, &parseCmd, &updateCmd)
+	rootCmd.AddCommand(&serveCmd, &migrateCmd, &parseCmd, &updateCmd, &workerCmd, &reparseCmd, &notifyTestCmd, &eventsCmd, &importCmd, &exportCmd)
 	return &rootCmd
 }
+
+func groupScoreThresholds(cfg *config.Config) domain.ScoreThresholds {
+	return domain.ScoreThresholds{
+		Low:      cfg.Group.LowScoreThreshold,
+		Critical: cfg.Group.CriticalScoreThreshold,
+	}
+}
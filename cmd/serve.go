@@ -42,17 +42,19 @@ func serve(cmd *cobra.Command, args []string) {
 	}()
 
 	// Data
-	groupRepo := data.NewGroupRepository(db)
+	groupRepo := data.NewGroupRepository(db, groupScoreThresholds(cfg))
 	ipRepo := data.NewIPRepository(db)
 	historyRepo := data.NewHistoryRepository(db)
 	scoreStatRepo := data.NewScoreStatRepository(db)
+	channelRepo := data.NewNotificationChannelRepository(db)
 
 	// Use Cases
-	groupUC := usecase.NewGroupUseCase(groupRepo, ipRepo, historyRepo, scoreStatRepo)
-	ipUC := usecase.NewIPUseCase(groupRepo, ipRepo, historyRepo)
+	groupUC := usecase.NewGroupUseCase(groupRepo, ipRepo, historyRepo, scoreStatRepo, channelRepo)
+	ipUC := usecase.NewIPUseCase(groupRepo, ipRepo, historyRepo, scoreStatRepo, data.NewIPEventRepository(db), data.NewUnitOfWork(db, groupScoreThresholds(cfg)), newAlerter(cfg, db))
 
 	// Handlers
 	groupHandler := handler.NewGroupHandler(groupUC, ipUC)
+	ipHandler := handler.NewIPHandler(ipUC)
 
 	// Middleware
 	validTokens := cfg.Auth.GetTokens()
@@ -66,7 +68,7 @@ func serve(cmd *cobra.Command, args []string) {
 
 	// Router
 	router := gin.Default()
-	handler.RegisterRoutes(router, groupHandler, authMiddleware)
+	handler.RegisterRoutes(router, groupHandler, ipHandler, authMiddleware)
 
 	// Server
 	addr := fmt.Sprintf(":%s", cfg.Server.Port)
@@ -9,8 +9,8 @@ import (
 	"strconv"
 	"time"
 
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
 	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure"
-	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure/senderscore"
 	"git.emercury.dev/emercury/senderscore/api/pkg/config"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
@@ -37,23 +37,33 @@ var submitCmd = cobra.Command{
 	Short: "Parse IP data and submit to API",
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := cmd.Context()
-		_ = config.Init(ctx)
+		cfg := config.Init(ctx)
+
+		// submit работает без доступа к БД, поэтому архив в БД недоступен
+		if cfg.Archive.Backend == infrastructure.ArchiveDB {
+			cfg.Archive.Backend = infrastructure.ArchiveNone
+		}
 
 		// Получение данных
-		client := &http.Client{Timeout: 15 * time.Second}
-		req := senderscore.NewRequestWrapper(client)
-		senderClient := senderscore.NewSenderClient(req)
-		report, err := senderClient.GetReport(submitIP)
+		provider, err := newScoreProvider(cfg, nil, &http.Client{Timeout: 15 * time.Second})
+		if err != nil {
+			logrus.WithError(err).Error("Failed to create score provider")
+			return
+		}
+
+		report, err := provider.Fetch(ctx, submitIP)
 		if err != nil {
-			logrus.WithError(err).Error("Failed to get report")
+			logRefreshError(logrus.WithField("ip", submitIP), err)
 			return
 		}
 
-		parser := infrastructure.NewParser(report)
-		result := parser.Parse()
+		if report.NoData {
+			logrus.WithField("ip", submitIP).Warn("Score provider has no data for IP, nothing to submit")
+			return
+		}
 
 		// Формирование payload для API
-		payload := buildSubmitPayload(submitIP, result)
+		payload := buildSubmitPayload(report)
 
 		// Отправка в API
 		if err := submitToAPI(payload); err != nil {
@@ -62,7 +72,7 @@ var submitCmd = cobra.Command{
 		}
 
 		// Отображение результата
-		displayResult(submitIP, result)
+		displayResult(submitIP, report)
 		logrus.Info("Successfully submitted to API")
 	},
 }
@@ -83,31 +93,23 @@ type HistoryEntry struct {
 	SpamTrap int    `json:"spam_trap"`
 }
 
-func buildSubmitPayload(ip string, result *infrastructure.Result) SubmitPayload {
-	volumes := make(map[string]int)
-	for _, v := range result.SSVolume {
-		volumes[v.Timestamp] = v.Value
-	}
-
-	history := make([]HistoryEntry, 0, len(result.SSTrend))
-	for _, p := range result.SSTrend {
-		ms, _ := strconv.ParseInt(p.Timestamp, 10, 64)
-		tm := time.Unix(0, ms*int64(time.Millisecond))
-
+func buildSubmitPayload(report *domain.ScoreReport) SubmitPayload {
+	history := make([]HistoryEntry, 0, len(report.History))
+	for _, p := range report.History {
 		history = append(history, HistoryEntry{
-			Date:     tm.Format("02.01.2006"),
-			Score:    p.Value,
-			Volume:   volumes[p.Timestamp],
-			SpamTrap: result.SpamTrap,
+			Date:     p.Date.Format("02.01.2006"),
+			Score:    p.Score,
+			Volume:   p.Volume,
+			SpamTrap: p.SpamTrap,
 		})
 	}
 
 	return SubmitPayload{
-		IP:         ip,
-		Score:      result.SenderScore,
-		SpamTrap:   result.SpamTrap,
-		Blocklists: result.Blocklists,
-		Complaints: result.Complaints,
+		IP:         report.IP,
+		Score:      report.Score,
+		SpamTrap:   report.SpamTrap,
+		Blocklists: report.Blocklists,
+		Complaints: report.Complaints,
 		History:    history,
 	}
 }
@@ -152,7 +154,7 @@ func submitToAPI(payload SubmitPayload) error {
 	return nil
 }
 
-func displayResult(ip string, result *infrastructure.Result) {
+func displayResult(ip string, report *domain.ScoreReport) {
 	purple := lipgloss.Color("#7D56F4")
 	baseStyle := lipgloss.NewStyle().Padding(0, 1)
 
@@ -164,17 +166,12 @@ func displayResult(ip string, result *infrastructure.Result) {
 		}).
 		Headers("Name", "Value").
 		Rows(
-			[]string{"Sender Score", strconv.Itoa(result.SenderScore)},
-			[]string{"Spam Traps", strconv.Itoa(result.SpamTrap)},
-			[]string{"Blocklists", result.Blocklists},
-			[]string{"Complaints", result.Complaints},
+			[]string{"Sender Score", strconv.Itoa(report.Score)},
+			[]string{"Spam Traps", strconv.Itoa(report.SpamTrap)},
+			[]string{"Blocklists", report.Blocklists},
+			[]string{"Complaints", report.Complaints},
 		)
 
-	volumes := make(map[string]int)
-	for _, v := range result.SSVolume {
-		volumes[v.Timestamp] = v.Value
-	}
-
 	detailTable := table.New().
 		Border(lipgloss.NormalBorder()).
 		BorderStyle(lipgloss.NewStyle().Foreground(purple)).
@@ -183,16 +180,11 @@ func displayResult(ip string, result *infrastructure.Result) {
 		}).
 		Headers("DATE", "SCORE", "VOLUME")
 
-	for _, p := range result.SSTrend {
-		ms, _ := strconv.ParseInt(p.Timestamp, 10, 64)
-		tm := time.Unix(0, ms*int64(time.Millisecond))
-
-		vol := volumes[p.Timestamp]
-
+	for _, p := range report.History {
 		detailTable.Row(
-			tm.Format("02.01.2006"),
-			strconv.Itoa(p.Value),
-			strconv.Itoa(vol),
+			p.Date.Format("02.01.2006"),
+			strconv.Itoa(p.Score),
+			strconv.Itoa(p.Volume),
 		)
 	}
 
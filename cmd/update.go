@@ -1,8 +1,8 @@
 package cmd
 
 import (
+	"context"
 	"net/http"
-	"strconv"
 	"time"
 
 	"git.emercury.dev/emercury/senderscore/api/internal/data"
@@ -12,12 +12,30 @@ import (
 	"git.emercury.dev/emercury/senderscore/api/pkg/config"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"gorm.io/gorm"
 )
 
+var (
+	updateAll         bool
+	updateGroupID     int
+	updateConcurrency int
+	updateRate        float64
+	updateBurst       int
+)
+
+func init() {
+	updateCmd.Flags().BoolVar(&updateAll, "all", false, "Refresh every tracked IP")
+	updateCmd.Flags().IntVar(&updateGroupID, "group", 0, "Refresh every IP of the given group_id")
+	updateCmd.Flags().IntVar(&updateConcurrency, "concurrency", 4, "Number of IPs refreshed in parallel in --all/--group mode")
+	updateCmd.Flags().Float64Var(&updateRate, "rate", 1, "Maximum requests per second to senderscore.org in --all/--group mode")
+	updateCmd.Flags().IntVar(&updateBurst, "burst", 1, "Maximum burst of requests to senderscore.org in --all/--group mode")
+	updateCmd.MarkFlagsMutuallyExclusive("all", "group")
+}
+
 var updateCmd = cobra.Command{
 	Use:   "update",
 	Short: "Update score for the oldest IP address",
-	Long:  "Fetches the oldest IP, retrieves current sender score data, and updates the database",
+	Long:  "Fetches the oldest IP, retrieves current sender score data, and updates the database. With --all or --group refreshes a whole set of IPs concurrently.",
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := cmd.Context()
 		cfg := config.Init(ctx)
@@ -30,99 +48,86 @@ var updateCmd = cobra.Command{
 		sqlDB, _ := db.DB()
 		defer sqlDB.Close()
 
-		groupRepo := data.NewGroupRepository(db)
+		groupRepo := data.NewGroupRepository(db, groupScoreThresholds(cfg))
 		ipRepo := data.NewIPRepository(db)
 		historyRepo := data.NewHistoryRepository(db)
+		scoreStatRepo := data.NewScoreStatRepository(db)
 
-		ipUC := usecase.NewIPUseCase(groupRepo, ipRepo, historyRepo)
-		oldestIP, err := ipUC.GetOldestIP(ctx)
-		if err != nil {
-			logrus.WithError(err).Fatal("Failed to get oldest IP")
-		}
-
-		logrus.WithFields(logrus.Fields{
-			"ip":         oldestIP.IP,
-			"updated_at": time.Unix(oldestIP.UpdatedAt, 0).Format("02.01.2006 15:04:05"),
-		}).Info("Processing oldest IP")
+		ipUC := usecase.NewIPUseCase(groupRepo, ipRepo, historyRepo, scoreStatRepo, data.NewIPEventRepository(db), data.NewUnitOfWork(db, groupScoreThresholds(cfg)), newAlerter(cfg, db))
 
-		client := &http.Client{Timeout: 15 * time.Second}
-		req := senderscore.NewRequestWrapper(client)
-		senderClient := senderscore.NewSenderClient(req)
-
-		report, err := senderClient.GetReport(oldestIP.IP)
-		if err != nil {
-			logrus.WithError(err).Error("Failed to get sender score report")
+		if updateAll || cmd.Flags().Changed("group") {
+			runUpdateMany(ctx, cfg, db, ipUC)
 			return
 		}
 
-		parser := infrastructure.NewParser(report)
-		result := parser.Parse()
-
-		logrus.WithFields(logrus.Fields{
-			"ip":          oldestIP.IP,
-			"score":       result.SenderScore,
-			"spam_traps":  result.SpamTrap,
-			"blocklists":  result.Blocklists,
-			"complaints":  result.Complaints,
-			"history_cnt": len(result.SSTrend),
-		}).Info("Parsed sender score data")
-
-		submitDTO := usecase.SubmitScoreDTO{
-			IP:         oldestIP.IP,
-			Score:      result.SenderScore,
-			SpamTrap:   result.SpamTrap,
-			Blocklists: result.Blocklists,
-			Complaints: result.Complaints,
-			History:    convertToHistoryDTO(result),
-		}
-
-		submitResult, err := ipUC.SubmitScore(ctx, submitDTO)
+		claimed, err := ipUC.ClaimStaleIPs(ctx, 1, claimLease(cfg.Worker.Lease, 1, cfg.Worker.FetchTimeout))
 		if err != nil {
-			logrus.WithError(err).Error("Failed to submit score")
+			logrus.WithError(err).Fatal("Failed to claim oldest IP")
+		}
+		if len(claimed) == 0 {
+			logrus.Info("No unclaimed IPs to update")
 			return
 		}
+		oldestIP := claimed[0]
 
 		logrus.WithFields(logrus.Fields{
-			"ip":              oldestIP.IP,
-			"ip_created":      submitResult.IPCreated,
-			"history_added":   submitResult.HistoryAdded,
-			"history_updated": submitResult.HistoryUpdated,
-		}).Info("Successfully updated IP score")
+			"ip":         oldestIP.IP,
+			"updated_at": time.Unix(oldestIP.UpdatedAt, 0).Format("02.01.2006 15:04:05"),
+		}).Info("Processing oldest IP")
 
-		updatedIP, err := ipRepo.GetByIP(ctx, oldestIP.IP)
+		provider, err := newScoreProvider(cfg, db, &http.Client{Timeout: 15 * time.Second})
 		if err != nil {
-			logrus.WithError(err).Warn("Failed to get updated IP for counter update")
+			logrus.WithError(err).Fatal("Failed to create score provider")
+		}
+
+		if _, err := refreshIP(ctx, ipUC, provider, oldestIP.IP, cfg.Worker.FetchTimeout); err != nil {
+			logRefreshError(logrus.WithField("ip", oldestIP.IP), err)
 			return
 		}
 
-		for _, groupID := range updatedIP.GroupIDs {
-			if err := groupRepo.UpdateCounters(ctx, groupID); err != nil {
-				logrus.WithError(err).WithField("group_id", groupID).Warn("Failed to update group counters")
-			}
+		if err := ipUC.ReleaseIP(ctx, oldestIP.ID); err != nil {
+			logrus.WithError(err).WithField("ip", oldestIP.IP).Warn("Failed to release IP claim")
 		}
 
 		logrus.Info("Update process completed successfully")
 	},
 }
 
-func convertToHistoryDTO(result *infrastructure.Result) []usecase.HistoryEntryDTO {
-	volumes := make(map[string]int)
-	for _, v := range result.SSVolume {
-		volumes[v.Timestamp] = v.Value
+func runUpdateMany(ctx context.Context, cfg *config.Config, db *gorm.DB, ipUC usecase.IPUseCase) {
+	if updateRate <= 0 {
+		logrus.WithField("rate", updateRate).Fatal("Rate must be positive")
 	}
 
-	history := make([]usecase.HistoryEntryDTO, 0, len(result.SSTrend))
-	for _, p := range result.SSTrend {
-		ms, _ := strconv.ParseInt(p.Timestamp, 10, 64)
-		tm := time.Unix(0, ms*int64(time.Millisecond))
-
-		history = append(history, usecase.HistoryEntryDTO{
-			Date:     tm.Format("02.01.2006"),
-			Score:    p.Value,
-			Volume:   volumes[p.Timestamp],
-			SpamTrap: result.SpamTrap,
-		})
+	var ips []*usecase.IPDTO
+	var err error
+	if updateAll {
+		ips, err = ipUC.ListAllIPs(ctx)
+	} else {
+		ips, err = ipUC.ListIPsByGroupID(ctx, updateGroupID)
 	}
+	if err != nil {
+		logrus.WithError(err).WithField("group_id", updateGroupID).Fatal("Failed to list IPs")
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	limiter := senderscore.NewRateLimiter(updateRate, updateBurst)
+	provider, err := newScoreProvider(cfg, db, senderscore.NewRateLimitedClient(client, limiter))
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to create score provider")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"total":       len(ips),
+		"concurrency": updateConcurrency,
+		"rate":        updateRate,
+	}).Info("Starting bulk update")
+
+	summary := runBulkUpdate(ctx, ipUC, provider, ips, updateConcurrency, claimLease(cfg.Worker.Lease, 1, cfg.Worker.FetchTimeout), cfg.Worker.FetchTimeout)
 
-	return history
+	logrus.WithFields(logrus.Fields{
+		"total":     summary.Total,
+		"refreshed": summary.Refreshed,
+		"failed":    summary.Failed,
+		"skipped":   summary.Skipped,
+	}).Info("Bulk update completed")
 }
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/data"
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure/senderscore"
+	"git.emercury.dev/emercury/senderscore/api/internal/usecase"
+	"git.emercury.dev/emercury/senderscore/api/pkg/config"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	workerInterval  time.Duration
+	workerBatchSize int
+)
+
+func init() {
+	workerCmd.Flags().DurationVar(&workerInterval, "interval", 0, "Delay between refresh cycles (overrides WORKER_INTERVAL)")
+	workerCmd.Flags().IntVar(&workerBatchSize, "batch-size", 0, "Number of stale IPs refreshed per cycle (overrides WORKER_BATCH_SIZE)")
+}
+
+var workerCmd = cobra.Command{
+	Use:   "worker",
+	Short: "Continuously refresh the stalest IP addresses",
+	Long:  "Runs until stopped, refreshing a batch of the least recently updated IPs on every cycle",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		cfg := config.Init(ctx)
+
+		interval := cfg.Worker.Interval
+		if cmd.Flags().Changed("interval") {
+			interval = workerInterval
+		}
+		batchSize := cfg.Worker.BatchSize
+		if cmd.Flags().Changed("batch-size") {
+			batchSize = workerBatchSize
+		}
+		if interval <= 0 || batchSize <= 0 {
+			logrus.WithFields(logrus.Fields{
+				"interval":   interval.String(),
+				"batch_size": batchSize,
+			}).Fatal("Worker interval and batch size must be positive")
+		}
+
+		db, err := infrastructure.NewDatabase(cfg.DB.DSN)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to connect to database")
+		}
+
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+
+		groupRepo := data.NewGroupRepository(db, groupScoreThresholds(cfg))
+		ipRepo := data.NewIPRepository(db)
+		historyRepo := data.NewHistoryRepository(db)
+		scoreStatRepo := data.NewScoreStatRepository(db)
+
+		ipUC := usecase.NewIPUseCase(groupRepo, ipRepo, historyRepo, scoreStatRepo, data.NewIPEventRepository(db), data.NewUnitOfWork(db, groupScoreThresholds(cfg)), newAlerter(cfg, db))
+
+		provider, err := newScoreProvider(cfg, db, &http.Client{Timeout: 15 * time.Second})
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to create score provider")
+		}
+
+		lease := claimLease(cfg.Worker.Lease, batchSize, cfg.Worker.FetchTimeout)
+
+		logrus.WithFields(logrus.Fields{
+			"interval":   interval.String(),
+			"batch_size": batchSize,
+			"lease":      lease.String(),
+			"provider":   provider.Name(),
+		}).Info("Starting worker")
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			runWorkerCycle(ctx, ipUC, provider, batchSize, lease, cfg.Worker.FetchTimeout)
+
+			select {
+			case <-ctx.Done():
+				logrus.Info("Worker stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+func runWorkerCycle(
+	ctx context.Context,
+	ipUC usecase.IPUseCase,
+	provider domain.ScoreProvider,
+	batchSize int,
+	lease time.Duration,
+	fetchTimeout time.Duration,
+) {
+	ips, err := ipUC.ClaimStaleIPs(ctx, batchSize, lease)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to claim stale IPs")
+		return
+	}
+
+	refreshed, failed := 0, 0
+	for _, ip := range ips {
+		if ctx.Err() != nil {
+			break
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"ip":         ip.IP,
+			"updated_at": time.Unix(ip.UpdatedAt, 0).Format("02.01.2006 15:04:05"),
+		}).Info("Processing stale IP")
+
+		// A failed IP keeps its lease until it expires so that it is not
+		// retried on every cycle.
+		if _, err := refreshIP(ctx, ipUC, provider, ip.IP, fetchTimeout); err != nil {
+			logRefreshError(logrus.WithField("ip", ip.IP), err)
+			failed++
+			if errors.Is(err, senderscore.ErrBlocked) {
+				break
+			}
+			continue
+		}
+		refreshed++
+
+		if err := ipUC.ReleaseIP(ctx, ip.ID); err != nil {
+			logrus.WithError(err).WithField("ip", ip.IP).Warn("Failed to release IP claim")
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"claimed":   len(ips),
+		"refreshed": refreshed,
+		"failed":    failed,
+	}).Info("Worker cycle completed")
+}
+
+// claimLease returns a lease long enough for a whole batch to be fetched one
+// IP at a time. A shorter lease would expire before the end of the batch is
+// reached and let another process claim the same IPs.
+func claimLease(lease time.Duration, batchSize int, fetchTimeout time.Duration) time.Duration {
+	minLease := time.Duration(batchSize) * fetchTimeout
+	if lease >= minLease {
+		return lease
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"lease":      lease.String(),
+		"batch_size": batchSize,
+		"min_lease":  minLease.String(),
+	}).Warn("WORKER_LEASE is shorter than batch size times WORKER_FETCH_TIMEOUT, using the minimum")
+	return minLease
+}
@@ -0,0 +1,72 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type alertStateRepository struct {
+	db *gorm.DB
+}
+
+func NewAlertStateRepository(db *gorm.DB) domain.AlertStateRepository {
+	return &alertStateRepository{db: db}
+}
+
+func (r *alertStateRepository) Activate(ctx context.Context, ipID uint, rule string) (*domain.AlertState, error) {
+	model := &AlertStateModel{
+		IpsID:       ipID,
+		Rule:        rule,
+		TriggeredAt: time.Now(),
+		Delivered:   []string{},
+	}
+
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(model)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to activate alert state: %w", result.Error)
+	}
+
+	// Правило уже было активно - возвращаем сохраненное состояние
+	if result.RowsAffected == 0 {
+		model = &AlertStateModel{}
+		if err := r.db.WithContext(ctx).
+			Where("ips_id = ? AND rule = ?", ipID, rule).
+			First(model).Error; err != nil {
+			return nil, fmt.Errorf("failed to get alert state: %w", err)
+		}
+	}
+
+	return &domain.AlertState{
+		Rule:        model.Rule,
+		TriggeredAt: model.TriggeredAt,
+		Delivered:   model.Delivered,
+		Completed:   model.Completed,
+	}, nil
+}
+
+func (r *alertStateRepository) SaveDelivery(ctx context.Context, ipID uint, state *domain.AlertState) error {
+	if err := r.db.WithContext(ctx).
+		Model(&AlertStateModel{}).
+		Where("ips_id = ? AND rule = ?", ipID, state.Rule).
+		Select("delivered", "completed").
+		Updates(&AlertStateModel{Delivered: state.Delivered, Completed: state.Completed}).Error; err != nil {
+		return fmt.Errorf("failed to save alert delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *alertStateRepository) Resolve(ctx context.Context, ipID uint, rule string) error {
+	if err := r.db.WithContext(ctx).
+		Where("ips_id = ? AND rule = ?", ipID, rule).
+		Delete(&AlertStateModel{}).Error; err != nil {
+		return fmt.Errorf("failed to resolve alert state: %w", err)
+	}
+	return nil
+}
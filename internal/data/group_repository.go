@@ -10,11 +10,12 @@ import (
 )
 
 type groupRepository struct {
-	db *gorm.DB
+	db         *gorm.DB
+	thresholds domain.ScoreThresholds
 }
 
-func NewGroupRepository(db *gorm.DB) domain.GroupRepository {
-	return &groupRepository{db: db}
+func NewGroupRepository(db *gorm.DB, thresholds domain.ScoreThresholds) domain.GroupRepository {
+	return &groupRepository{db: db, thresholds: thresholds}
 }
 
 func (r *groupRepository) Create(ctx context.Context, group *domain.Group) error {
@@ -48,15 +49,51 @@ func (r *groupRepository) GetByGroupID(ctx context.Context, groupID int) (*domai
 	return toGroupDomain(&model), nil
 }
 
-func (r *groupRepository) List(ctx context.Context, offset, limit int) ([]*domain.Group, int64, error) {
+var groupSortColumns = map[string]string{
+	domain.GroupSortGroupID:   "group_id",
+	domain.GroupSortName:      "group_name",
+	domain.GroupSortSpamTraps: "spam_trap_count",
+	domain.GroupSortIPsCount:  "ips_count",
+}
+
+func (r *groupRepository) List(ctx context.Context, filter domain.GroupFilter, offset, limit int) ([]*domain.Group, int64, error) {
 	var models []GroupModel
 	var total int64
 
-	if err := r.db.WithContext(ctx).Model(&GroupModel{}).Count(&total).Error; err != nil {
+	query := r.db.WithContext(ctx).Model(&GroupModel{})
+	if filter.Search != "" {
+		query = query.Where(`group_name ILIKE ? ESCAPE '\'`, containsPattern(filter.Search))
+	}
+	if filter.MinSpamTraps != nil {
+		query = query.Where("spam_trap_count >= ?", *filter.MinSpamTraps)
+	}
+	if filter.MaxSpamTraps != nil {
+		query = query.Where("spam_trap_count <= ?", *filter.MaxSpamTraps)
+	}
+	if filter.MinIPs != nil {
+		query = query.Where("ips_count >= ?", *filter.MinIPs)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to count groups: %w", err)
 	}
 
-	if err := r.db.WithContext(ctx).Offset(offset).Limit(limit).Find(&models).Error; err != nil {
+	column, ok := groupSortColumns[filter.SortBy]
+	if !ok {
+		column = "group_id"
+	}
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+
+	// id как второй ключ делает порядок детерминированным при равных значениях
+	if err := query.
+		Order(column + " " + direction).
+		Order("id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&models).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to list groups: %w", err)
 	}
 
@@ -108,9 +145,58 @@ func (r *groupRepository) UpdateCounters(ctx context.Context, groupID int) error
 		return fmt.Errorf("failed to sum spam traps: %w", err)
 	}
 
+	// Оценочные метрики считаются только по IP с актуальными данными:
+	// pending еще не проверялись, у no_data хранится устаревший score.
+	var scores struct {
+		AvgScore           float64
+		MinScore           int
+		LowScoreCount      int
+		CriticalScoreCount int
+		BlocklistedCount   int
+		AvgComplaintRate   float64
+	}
+	if err := r.db.WithContext(ctx).
+		Table("sender_score_ips").
+		Joins("JOIN sender_score_group_ips ON sender_score_ips.id = sender_score_group_ips.ip_id").
+		Where("sender_score_group_ips.group_id = ? AND sender_score_ips.status = ?", group.ID, domain.IPStatusOK).
+		Select(`COALESCE(AVG(score), 0) AS avg_score,
+			COALESCE(MIN(score), 0) AS min_score,
+			COUNT(*) FILTER (WHERE score < ?) AS low_score_count,
+			COUNT(*) FILTER (WHERE score < ?) AS critical_score_count,
+			COUNT(*) FILTER (WHERE blocklist_count <> 0) AS blocklisted_count,
+			COALESCE(AVG(complaint_rate), 0) AS avg_complaint_rate`,
+			r.thresholds.Low, r.thresholds.Critical).
+		Scan(&scores).Error; err != nil {
+		return fmt.Errorf("failed to aggregate scores: %w", err)
+	}
+
+	// Вес IP - объем из его последней записи истории
+	var weightedScore float64
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT COALESCE(SUM(ips.score * latest.volume)::float / NULLIF(SUM(latest.volume), 0), 0)
+		FROM sender_score_ips ips
+		JOIN sender_score_group_ips gi ON ips.id = gi.ip_id
+		JOIN (
+			SELECT DISTINCT ON (ips_id) ips_id, volume
+			FROM sender_score_histories
+			ORDER BY ips_id, time DESC
+		) latest ON latest.ips_id = ips.id
+		WHERE gi.group_id = ? AND ips.status = ?`,
+		group.ID, domain.IPStatusOK).
+		Scan(&weightedScore).Error; err != nil {
+		return fmt.Errorf("failed to compute weighted score: %w", err)
+	}
+
 	if err := r.db.WithContext(ctx).Model(&GroupModel{}).Where("id = ?", group.ID).Updates(map[string]interface{}{
-		"ips_count":       ipsCount,
-		"spam_trap_count": totalSpamTrap,
+		"ips_count":            ipsCount,
+		"spam_trap_count":      totalSpamTrap,
+		"avg_score":            scores.AvgScore,
+		"min_score":            scores.MinScore,
+		"weighted_score":       weightedScore,
+		"low_score_count":      scores.LowScoreCount,
+		"critical_score_count": scores.CriticalScoreCount,
+		"blocklisted_count":    scores.BlocklistedCount,
+		"avg_complaint_rate":   scores.AvgComplaintRate,
 	}).Error; err != nil {
 		return fmt.Errorf("failed to update counters: %w", err)
 	}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"git.emercury.dev/emercury/senderscore/api/internal/domain"
 	"gorm.io/gorm"
@@ -51,6 +53,81 @@ func (r *historyRepository) ListByIPID(ctx context.Context, ipID uint) ([]*domai
 	return histories, nil
 }
 
+// ListByIPIDAndRange returns the IP history ordered by date. A zero from or
+// to leaves that side of the range open.
+func (r *historyRepository) ListByIPIDAndRange(ctx context.Context, ipID uint, from, to time.Time) ([]*domain.History, error) {
+	query := r.db.WithContext(ctx).Where("ips_id = ?", ipID)
+	if !from.IsZero() {
+		query = query.Where("time >= ?", from.Format("2006-01-02"))
+	}
+	if !to.IsZero() {
+		query = query.Where("time <= ?", to.Format("2006-01-02"))
+	}
+
+	var models []HistoryModel
+	if err := query.Order("time ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list history by IP and range: %w", err)
+	}
+
+	histories := make([]*domain.History, len(models))
+	for i, model := range models {
+		histories[i] = toHistoryDomain(&model)
+	}
+
+	return histories, nil
+}
+
+// AggregateByGroup returns one point per day across all IPs of the group,
+// identified by its internal id. A zero from or to leaves that side of the
+// range open.
+func (r *historyRepository) AggregateByGroup(ctx context.Context, groupID uint, from, to time.Time) ([]*domain.GroupHistoryPoint, error) {
+	query := r.db.WithContext(ctx).
+		Table("sender_score_histories").
+		Joins("JOIN sender_score_group_ips ON sender_score_histories.ips_id = sender_score_group_ips.ip_id").
+		Where("sender_score_group_ips.group_id = ?", groupID)
+	if !from.IsZero() {
+		query = query.Where("sender_score_histories.time >= ?", from.Format("2006-01-02"))
+	}
+	if !to.IsZero() {
+		query = query.Where("sender_score_histories.time <= ?", to.Format("2006-01-02"))
+	}
+
+	var rows []struct {
+		Date     time.Time
+		AvgScore float64
+		MinScore int
+		Volume   int
+		SpamTrap int
+		IPsCount int
+	}
+	if err := query.
+		Select(`sender_score_histories.time AS date,
+			AVG(sender_score_histories.score) AS avg_score,
+			MIN(sender_score_histories.score) AS min_score,
+			COALESCE(SUM(sender_score_histories.volume), 0) AS volume,
+			COALESCE(SUM(sender_score_histories.spam_trap), 0) AS spam_trap,
+			COUNT(DISTINCT sender_score_histories.ips_id) AS ips_count`).
+		Group("sender_score_histories.time").
+		Order("sender_score_histories.time ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate group history: %w", err)
+	}
+
+	points := make([]*domain.GroupHistoryPoint, len(rows))
+	for i, row := range rows {
+		points[i] = &domain.GroupHistoryPoint{
+			Date:     row.Date,
+			AvgScore: row.AvgScore,
+			MinScore: row.MinScore,
+			Volume:   row.Volume,
+			SpamTrap: row.SpamTrap,
+			IPsCount: row.IPsCount,
+		}
+	}
+
+	return points, nil
+}
+
 func (r *historyRepository) Update(ctx context.Context, history *domain.History) error {
 	model := toHistoryModel(history)
 	if err := r.db.WithContext(ctx).Save(model).Error; err != nil {
@@ -59,6 +136,40 @@ func (r *historyRepository) Update(ctx context.Context, history *domain.History)
 	return nil
 }
 
+// Upsert writes all histories in one statement, replacing the values of
+// entries that already exist for the same IP and date. It returns how many
+// entries were newly inserted.
+func (r *historyRepository) Upsert(ctx context.Context, histories []*domain.History) (int, error) {
+	if len(histories) == 0 {
+		return 0, nil
+	}
+
+	values := make([]string, len(histories))
+	args := make([]interface{}, 0, len(histories)*5)
+	for i, history := range histories {
+		values[i] = "(?, ?, ?, ?, ?)"
+		args = append(args, history.IPsID, history.Score, history.SpamTrap, history.Volume, history.Time.Format("2006-01-02"))
+	}
+
+	var inserted []bool
+	err := r.db.WithContext(ctx).Raw(`INSERT INTO sender_score_histories (ips_id, score, spam_trap, volume, time)
+		VALUES `+strings.Join(values, ", ")+`
+		ON CONFLICT (ips_id, time) DO UPDATE
+		SET score = EXCLUDED.score, spam_trap = EXCLUDED.spam_trap, volume = EXCLUDED.volume
+		RETURNING (xmax = 0)`, args...).Scan(&inserted).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to upsert history: %w", err)
+	}
+
+	added := 0
+	for _, ok := range inserted {
+		if ok {
+			added++
+		}
+	}
+	return added, nil
+}
+
 func (r *historyRepository) DeleteByIPID(ctx context.Context, ipID uint) error {
 	if err := r.db.WithContext(ctx).Where("ips_id = ?", ipID).Delete(&HistoryModel{}).Error; err != nil {
 		return fmt.Errorf("failed to delete history by IP: %w", err)
@@ -0,0 +1,59 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type ipEventRepository struct {
+	db *gorm.DB
+}
+
+// NewIPEventRepository returns an append-only store for IP change events.
+func NewIPEventRepository(db *gorm.DB) domain.IPEventRepository {
+	return &ipEventRepository{db: db}
+}
+
+func (r *ipEventRepository) Create(ctx context.Context, events []*domain.IPEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	models := make([]*IPEventModel, len(events))
+	for i, event := range events {
+		models[i] = toIPEventModel(event)
+	}
+
+	if err := r.db.WithContext(ctx).Create(&models).Error; err != nil {
+		return fmt.Errorf("failed to create IP events: %w", err)
+	}
+
+	for i, model := range models {
+		events[i].ID = model.ID
+	}
+	return nil
+}
+
+// ListByIPID returns the IP events oldest first. An empty field returns
+// events for all fields.
+func (r *ipEventRepository) ListByIPID(ctx context.Context, ipID uint, field string) ([]*domain.IPEvent, error) {
+	query := r.db.WithContext(ctx).Where("ips_id = ?", ipID)
+	if field != "" {
+		query = query.Where("field = ?", field)
+	}
+
+	var models []IPEventModel
+	if err := query.Order("created_at ASC, id ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list IP events: %w", err)
+	}
+
+	events := make([]*domain.IPEvent, len(models))
+	for i, model := range models {
+		events[i] = toIPEventDomain(&model)
+	}
+
+	return events, nil
+}
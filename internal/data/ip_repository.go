@@ -4,11 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"git.emercury.dev/emercury/senderscore/api/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// batchSize limits the rows of one multi-row statement so that it stays
+// well below the Postgres bind parameter limit.
+const batchSize = 500
+
 type ipRepository struct {
 	db *gorm.DB
 }
@@ -26,6 +33,26 @@ func (r *ipRepository) Create(ctx context.Context, ip *domain.IP) error {
 	return nil
 }
 
+// CreateBatch inserts all IPs with multi-row statements and sets their ids.
+func (r *ipRepository) CreateBatch(ctx context.Context, ips []*domain.IP) error {
+	if len(ips) == 0 {
+		return nil
+	}
+
+	models := make([]*IPModel, len(ips))
+	for i, ip := range ips {
+		models[i] = toIPModel(ip)
+	}
+	if err := r.db.WithContext(ctx).CreateInBatches(models, batchSize).Error; err != nil {
+		return fmt.Errorf("failed to create IPs: %w", err)
+	}
+
+	for i, model := range models {
+		ips[i].ID = model.ID
+	}
+	return nil
+}
+
 func (r *ipRepository) GetByID(ctx context.Context, id uint) (*domain.IP, error) {
 	var model IPModel
 	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
@@ -46,6 +73,12 @@ func (r *ipRepository) GetByID(ctx context.Context, id uint) (*domain.IP, error)
 }
 
 func (r *ipRepository) GetByIP(ctx context.Context, ipAddress string) (*domain.IP, error) {
+	// Строка, которая не является адресом, не может быть в колонке inet
+	ipAddress, err := domain.CanonicalIP(ipAddress)
+	if err != nil {
+		return nil, domain.ErrIPNotFound
+	}
+
 	var model IPModel
 	if err := r.db.WithContext(ctx).Where("ip = ?", ipAddress).First(&model).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -64,6 +97,27 @@ func (r *ipRepository) GetByIP(ctx context.Context, ipAddress string) (*domain.I
 	return ip, nil
 }
 
+// ListByAddresses returns the IPs among addresses that are already tracked.
+// Addresses must be canonical.
+func (r *ipRepository) ListByAddresses(ctx context.Context, addresses []string) ([]*domain.IP, error) {
+	ips := make([]*domain.IP, 0, len(addresses))
+	for start := 0; start < len(addresses); start += batchSize {
+		end := start + batchSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+
+		var models []IPModel
+		if err := r.db.WithContext(ctx).Where("ip IN ?", addresses[start:end]).Find(&models).Error; err != nil {
+			return nil, fmt.Errorf("failed to list IPs by address: %w", err)
+		}
+		for i := range models {
+			ips = append(ips, toIPDomain(&models[i]))
+		}
+	}
+	return ips, nil
+}
+
 func (r *ipRepository) GetOldestIP(ctx context.Context) (*domain.IP, error) {
 	var model IPModel
 	if err := r.db.WithContext(ctx).
@@ -85,6 +139,137 @@ func (r *ipRepository) GetOldestIP(ctx context.Context) (*domain.IP, error) {
 	return ip, nil
 }
 
+func (r *ipRepository) ClaimStale(ctx context.Context, limit int, lease time.Duration) ([]*domain.IP, error) {
+	var models []IPModel
+	now := time.Now()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("claimed_until IS NULL OR claimed_until < ?", now).
+			Order("updated_at ASC").
+			Limit(limit).
+			Find(&models).Error; err != nil {
+			return err
+		}
+
+		if len(models) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(models))
+		for i, model := range models {
+			ids[i] = model.ID
+		}
+
+		return tx.Model(&IPModel{}).
+			Where("id IN ?", ids).
+			Update("claimed_until", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim stale IPs: %w", err)
+	}
+
+	return r.toIPDomainsWithGroups(ctx, models)
+}
+
+func (r *ipRepository) Claim(ctx context.Context, id uint, lease time.Duration) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Model(&IPModel{}).
+		Where("id = ? AND (claimed_until IS NULL OR claimed_until < ?)", id, now).
+		Update("claimed_until", now.Add(lease))
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim IP: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *ipRepository) ReleaseClaim(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Model(&IPModel{}).Where("id = ?", id).Update("claimed_until", nil).Error; err != nil {
+		return fmt.Errorf("failed to release IP claim: %w", err)
+	}
+	return nil
+}
+
+func (r *ipRepository) ListAll(ctx context.Context) ([]*domain.IP, error) {
+	var models []IPModel
+	if err := r.db.WithContext(ctx).
+		Order("updated_at ASC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list IPs: %w", err)
+	}
+
+	ips := make([]*domain.IP, len(models))
+	for i, model := range models {
+		ips[i] = toIPDomain(&model)
+	}
+
+	return ips, nil
+}
+
+func (r *ipRepository) List(ctx context.Context, filter domain.IPFilter, offset, limit int) ([]*domain.IP, int64, error) {
+	query := r.db.WithContext(ctx).Model(&IPModel{})
+
+	if filter.GroupID != 0 {
+		query = query.
+			Joins("JOIN sender_score_group_ips ON sender_score_ips.id = sender_score_group_ips.ip_id").
+			Joins("JOIN sender_score_groups ON sender_score_groups.id = sender_score_group_ips.group_id").
+			Where("sender_score_groups.group_id = ?", filter.GroupID)
+	}
+	if filter.Search != "" {
+		query = query.Where(`host(sender_score_ips.ip) LIKE ? ESCAPE '\'`, containsPattern(filter.Search))
+	}
+	if filter.Status != "" {
+		query = query.Where("sender_score_ips.status = ?", filter.Status)
+	}
+	if filter.MinScore != nil {
+		query = query.Where("sender_score_ips.score >= ?", *filter.MinScore)
+	}
+	if filter.MaxScore != nil {
+		query = query.Where("sender_score_ips.score <= ?", *filter.MaxScore)
+	}
+	if filter.Blocklisted != nil {
+		if *filter.Blocklisted {
+			query = query.Where("sender_score_ips.blocklist_count <> 0")
+		} else {
+			query = query.Where("sender_score_ips.blocklist_count = 0")
+		}
+	}
+	if filter.MinComplaintRate != nil {
+		query = query.Where("sender_score_ips.complaint_rate >= ?", *filter.MinComplaintRate)
+	}
+	if filter.HasSpamTraps != nil {
+		if *filter.HasSpamTraps {
+			query = query.Where("sender_score_ips.spam_trap > 0")
+		} else {
+			query = query.Where("sender_score_ips.spam_trap = 0")
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count IPs: %w", err)
+	}
+
+	var models []IPModel
+	if err := query.
+		Select("sender_score_ips.*").
+		Order("sender_score_ips.id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list IPs: %w", err)
+	}
+
+	ips, err := r.toIPDomainsWithGroups(ctx, models)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return ips, total, nil
+}
+
 func (r *ipRepository) ListByGroupID(ctx context.Context, groupID int) ([]*domain.IP, error) {
 	var group GroupModel
 	if err := r.db.WithContext(ctx).Where("group_id = ?", groupID).First(&group).Error; err != nil {
@@ -102,28 +287,20 @@ func (r *ipRepository) ListByGroupID(ctx context.Context, groupID int) ([]*domai
 		return nil, fmt.Errorf("failed to list IPs by group: %w", err)
 	}
 
-	ips := make([]*domain.IP, len(models))
-	for i, model := range models {
-		groupIDs, err := r.getGroupIDsForIP(ctx, model.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load group IDs for IP %d: %w", model.ID, err)
-		}
-		ip := toIPDomain(&model)
-		ip.GroupIDs = groupIDs
-		ips[i] = ip
-	}
-
-	return ips, nil
+	return r.toIPDomainsWithGroups(ctx, models)
 }
 
 func (r *ipRepository) Update(ctx context.Context, ip *domain.IP) error {
 	model := toIPModel(ip)
 	if err := r.db.WithContext(ctx).Model(&IPModel{}).Where("id = ?", model.ID).Updates(map[string]interface{}{
-		"score":      model.Score,
-		"spam_trap":  model.SpamTrap,
-		"blocklists": model.Blocklists,
-		"complaints": model.Complaints,
-		"updated_at": model.UpdatedAt,
+		"score":           model.Score,
+		"spam_trap":       model.SpamTrap,
+		"blocklists":      model.Blocklists,
+		"complaints":      model.Complaints,
+		"blocklist_count": model.BlocklistCount,
+		"complaint_rate":  model.ComplaintRate,
+		"status":          model.Status,
+		"updated_at":      model.UpdatedAt,
 	}).Error; err != nil {
 		return fmt.Errorf("failed to update IP: %w", err)
 	}
@@ -156,6 +333,33 @@ func (r *ipRepository) AddToGroup(ctx context.Context, ipID uint, groupID int) e
 	return nil
 }
 
+// AddToGroups links IPs to groups with multi-row statements. Links to
+// groups that do not exist are ignored, as are links that already exist.
+func (r *ipRepository) AddToGroups(ctx context.Context, links []domain.GroupLink) error {
+	for start := 0; start < len(links); start += batchSize {
+		end := start + batchSize
+		if end > len(links) {
+			end = len(links)
+		}
+
+		values := make([]string, 0, end-start)
+		args := make([]interface{}, 0, (end-start)*2)
+		for _, link := range links[start:end] {
+			values = append(values, "(?::bigint, ?::bigint)")
+			args = append(args, link.IPID, link.GroupID)
+		}
+
+		if err := r.db.WithContext(ctx).Exec(`INSERT INTO sender_score_group_ips (ip_id, group_id)
+			SELECT v.ip_id, g.id
+			FROM (VALUES `+strings.Join(values, ", ")+`) AS v(ip_id, group_id)
+			JOIN sender_score_groups g ON g.group_id = v.group_id
+			ON CONFLICT DO NOTHING`, args...).Error; err != nil {
+			return fmt.Errorf("failed to add IPs to groups: %w", err)
+		}
+	}
+	return nil
+}
+
 func (r *ipRepository) RemoveFromGroup(ctx context.Context, ipID uint, groupID int) error {
 	var group GroupModel
 	if err := r.db.WithContext(ctx).Where("group_id = ?", groupID).First(&group).Error; err != nil {
@@ -186,6 +390,50 @@ func (r *ipRepository) IsIPInOtherGroups(ctx context.Context, ipID uint, exclude
 	return count > 0, nil
 }
 
+// toIPDomainsWithGroups maps the models and loads the group IDs of all of
+// them with one query per batch instead of one per IP.
+func (r *ipRepository) toIPDomainsWithGroups(ctx context.Context, models []IPModel) ([]*domain.IP, error) {
+	ids := make([]uint, len(models))
+	for i, model := range models {
+		ids[i] = model.ID
+	}
+
+	groupIDs := make(map[uint][]int, len(models))
+	for start := 0; start < len(ids); start += batchSize {
+		var rows []struct {
+			IPID    uint `gorm:"column:ip_id"`
+			GroupID int  `gorm:"column:group_id"`
+		}
+		if err := r.db.WithContext(ctx).
+			Table("sender_score_group_ips").
+			Select("sender_score_group_ips.ip_id, sender_score_groups.group_id").
+			Joins("JOIN sender_score_groups ON sender_score_groups.id = sender_score_group_ips.group_id").
+			Where("sender_score_group_ips.ip_id IN ?", ids[start:min(start+batchSize, len(ids))]).
+			Order("sender_score_groups.group_id").
+			Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to load group IDs: %w", err)
+		}
+		for _, row := range rows {
+			groupIDs[row.IPID] = append(groupIDs[row.IPID], row.GroupID)
+		}
+	}
+
+	ips := make([]*domain.IP, len(models))
+	for i := range models {
+		ip := toIPDomain(&models[i])
+		ip.GroupIDs = groupIDs[ip.ID]
+		ips[i] = ip
+	}
+	return ips, nil
+}
+
+// containsPattern builds a LIKE pattern matching values that contain s
+// literally; it is used with ESCAPE '\'.
+func containsPattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+	return "%" + s + "%"
+}
+
 func (r *ipRepository) getGroupIDsForIP(ctx context.Context, ipID uint) ([]int, error) {
 	var internalIDs []uint
 	if err := r.db.WithContext(ctx).
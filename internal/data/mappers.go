@@ -1,6 +1,8 @@
 package data
 
 import (
+	"net/netip"
+
 	"git.emercury.dev/emercury/senderscore/api/internal/domain"
 )
 
@@ -14,6 +16,14 @@ func toGroupDomain(model *GroupModel) *domain.Group {
 		GroupName:     model.GroupName,
 		SpamTrapCount: model.SpamTrapCount,
 		IPsCount:      model.IPsCount,
+
+		AvgScore:           model.AvgScore,
+		MinScore:           model.MinScore,
+		WeightedScore:      model.WeightedScore,
+		LowScoreCount:      model.LowScoreCount,
+		CriticalScoreCount: model.CriticalScoreCount,
+		BlocklistedCount:   model.BlocklistedCount,
+		AvgComplaintRate:   model.AvgComplaintRate,
 	}
 }
 
@@ -27,6 +37,14 @@ func toGroupModel(entity *domain.Group) *GroupModel {
 		GroupName:     entity.GroupName,
 		SpamTrapCount: entity.SpamTrapCount,
 		IPsCount:      entity.IPsCount,
+
+		AvgScore:           entity.AvgScore,
+		MinScore:           entity.MinScore,
+		WeightedScore:      entity.WeightedScore,
+		LowScoreCount:      entity.LowScoreCount,
+		CriticalScoreCount: entity.CriticalScoreCount,
+		BlocklistedCount:   entity.BlocklistedCount,
+		AvgComplaintRate:   entity.AvgComplaintRate,
 	}
 }
 
@@ -36,13 +54,17 @@ func toIPDomain(model *IPModel) *domain.IP {
 	}
 	return &domain.IP{
 		ID:         model.ID,
-		IP:         model.IP,
+		IP:         hostAddress(model.IP),
 		Score:      model.Score,
 		SpamTrap:   model.SpamTrap,
 		Blocklists: model.Blocklists,
 		Complaints: model.Complaints,
+		Status:     model.Status,
 		UpdatedAt:  model.UpdatedAt,
 		GroupIDs:   []int{}, // Будет заполнено в репозитории
+
+		BlocklistCount: model.BlocklistCount,
+		ComplaintRate:  model.ComplaintRate,
 	}
 }
 
@@ -57,7 +79,11 @@ func toIPModel(entity *domain.IP) *IPModel {
 		SpamTrap:   entity.SpamTrap,
 		Blocklists: entity.Blocklists,
 		Complaints: entity.Complaints,
+		Status:     entity.Status,
 		UpdatedAt:  entity.UpdatedAt,
+
+		BlocklistCount: entity.BlocklistCount,
+		ComplaintRate:  entity.ComplaintRate,
 	}
 }
 
@@ -93,24 +119,71 @@ func toScoreStatDomain(model *ScoreStatModel) *domain.ScoreStat {
 	if model == nil {
 		return nil
 	}
-	return &domain.ScoreStat{
+	stat := &domain.ScoreStat{
 		ID:     model.ID,
 		IPsID:  model.IpsID,
 		Score:  model.Score,
 		Result: model.Result,
 		Date:   model.Date,
 	}
+	if model.ObservedAt != nil {
+		stat.ObservedAt = *model.ObservedAt
+	}
+	return stat
 }
 
 func toScoreStatModel(entity *domain.ScoreStat) *ScoreStatModel {
 	if entity == nil {
 		return nil
 	}
-	return &ScoreStatModel{
+	model := &ScoreStatModel{
 		ID:     entity.ID,
 		IpsID:  entity.IPsID,
 		Score:  entity.Score,
 		Result: entity.Result,
 		Date:   entity.Date,
 	}
+	if !entity.ObservedAt.IsZero() {
+		observedAt := entity.ObservedAt
+		model.ObservedAt = &observedAt
+	}
+	return model
+}
+
+func toIPEventDomain(model *IPEventModel) *domain.IPEvent {
+	if model == nil {
+		return nil
+	}
+	return &domain.IPEvent{
+		ID:        model.ID,
+		IPsID:     model.IpsID,
+		Field:     model.Field,
+		OldValue:  model.OldValue,
+		NewValue:  model.NewValue,
+		CreatedAt: model.CreatedAt,
+	}
+}
+
+func toIPEventModel(entity *domain.IPEvent) *IPEventModel {
+	if entity == nil {
+		return nil
+	}
+	return &IPEventModel{
+		ID:        entity.ID,
+		IpsID:     entity.IPsID,
+		Field:     entity.Field,
+		OldValue:  entity.OldValue,
+		NewValue:  entity.NewValue,
+		CreatedAt: entity.CreatedAt,
+	}
+}
+
+// hostAddress drops the /32 or /128 suffix the driver adds when reading an
+// inet column.
+func hostAddress(value string) string {
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil || !prefix.IsSingleIP() {
+		return value
+	}
+	return prefix.Addr().String()
 }
@@ -8,6 +8,14 @@ type GroupModel struct {
 	GroupName     string `gorm:"type:varchar(255);comment:Group Name"`
 	SpamTrapCount int    `gorm:"default:0;index:idx_group_counts;comment:Spam Trap Count"`
 	IPsCount      int    `gorm:"default:0;index:idx_group_counts;comment:IPs Count"`
+
+	AvgScore           float64 `gorm:"default:0;comment:Average Score"`
+	MinScore           int     `gorm:"default:0;comment:Min Score"`
+	WeightedScore      float64 `gorm:"default:0;comment:Volume Weighted Score"`
+	LowScoreCount      int     `gorm:"default:0;comment:Low Score IPs Count"`
+	CriticalScoreCount int     `gorm:"default:0;comment:Critical Score IPs Count"`
+	BlocklistedCount   int     `gorm:"default:0;comment:Blocklisted IPs Count"`
+	AvgComplaintRate   float64 `gorm:"default:0;comment:Average Complaint Rate"`
 }
 
 func (GroupModel) TableName() string {
@@ -16,13 +24,19 @@ func (GroupModel) TableName() string {
 
 type IPModel struct {
 	ID         uint      `gorm:"primaryKey;comment:ID"`
-	IP         string    `gorm:"type:char(16);uniqueIndex:idx_unique_ip;comment:IP"`
+	IP         string    `gorm:"type:inet;uniqueIndex:idx_unique_ip;comment:IP"`
 	Score      int       `gorm:"default:0;index:idx_ips_score_trap;comment:Score"`
 	SpamTrap   int       `gorm:"default:0;index:idx_ips_score_trap;comment:Spam Trap"`
 	Blocklists string    `gorm:"type:varchar(50);comment:Blocklists"`
 	Complaints string    `gorm:"type:varchar(50);comment:Complaints"`
+	Status     string    `gorm:"type:varchar(20);default:ok;comment:Status"`
 	UpdatedAt  time.Time `gorm:"index:idx_ips_updated;comment:Updated"`
 
+	BlocklistCount int     `gorm:"default:0;index:idx_ips_blocklist_count;comment:Blocklist Count"`
+	ComplaintRate  float64 `gorm:"default:0;comment:Complaint Rate Percent"`
+
+	ClaimedUntil *time.Time `gorm:"index:idx_ips_claimed;comment:Claimed Until"`
+
 	Groups []GroupModel `gorm:"many2many:sender_score_group_ips;joinForeignKey:IPID;joinReferences:GroupID;"`
 }
 
@@ -44,11 +58,11 @@ func (GroupIPModel) TableName() string {
 
 type HistoryModel struct {
 	ID       uint      `gorm:"primaryKey;comment:ID"`
-	IpsID    uint      `gorm:"not null;index;comment:IPs ID"`
+	IpsID    uint      `gorm:"not null;index;uniqueIndex:idx_hist_ip_time,priority:1;comment:IPs ID"`
 	Score    int       `gorm:"default:0;index:idx_hist_score;comment:Score"`
 	SpamTrap int       `gorm:"default:0;index:idx_hist_trap;comment:Spam Trap"`
 	Volume   int       `gorm:"default:0;comment:Volume"`
-	Time     time.Time `gorm:"type:date;index:idx_hist_time;comment:Date"`
+	Time     time.Time `gorm:"type:date;index:idx_hist_time;uniqueIndex:idx_hist_ip_time,priority:2;comment:Date"`
 
 	IPRecord IPModel `gorm:"foreignKey:IpsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
@@ -59,10 +73,12 @@ func (HistoryModel) TableName() string {
 
 type ScoreStatModel struct {
 	ID     uint      `gorm:"primaryKey;comment:ID"`
-	IpsID  uint      `gorm:"not null;index;comment:IPs ID"`
+	IpsID  uint      `gorm:"not null;index;uniqueIndex:idx_stats_ip_observed,priority:1;comment:IPs ID"`
 	Score  int       `gorm:"default:0;index:idx_stats_score;comment:Score"`
 	Result int       `gorm:"default:0;index:idx_stats_result;comment:Result"`
 	Date   time.Time `gorm:"type:date;index:idx_stats_date;comment:Date"`
+	// Старые записи без observed_at (NULL) уникальный индекс не затрагивает
+	ObservedAt *time.Time `gorm:"uniqueIndex:idx_stats_ip_observed,priority:2;comment:Observed At"`
 
 	IPRecord IPModel `gorm:"foreignKey:IpsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
@@ -70,3 +86,58 @@ type ScoreStatModel struct {
 func (ScoreStatModel) TableName() string {
 	return "sender_score_score_stats"
 }
+
+type RawReportModel struct {
+	ID        uint      `gorm:"primaryKey;comment:ID"`
+	IP        string    `gorm:"type:varchar(45);index:idx_raw_ip_fetched;comment:IP"`
+	Source    string    `gorm:"type:varchar(32);comment:Source"`
+	FetchedAt time.Time `gorm:"index:idx_raw_ip_fetched;index:idx_raw_fetched;comment:Fetched At"`
+	Body      []byte    `gorm:"type:bytea;comment:Gzipped Body"`
+}
+
+func (RawReportModel) TableName() string {
+	return "sender_score_raw_reports"
+}
+
+type AlertStateModel struct {
+	ID          uint      `gorm:"primaryKey;comment:ID"`
+	IpsID       uint      `gorm:"not null;uniqueIndex:idx_alert_state;comment:IPs ID"`
+	Rule        string    `gorm:"type:varchar(32);uniqueIndex:idx_alert_state;comment:Rule"`
+	TriggeredAt time.Time `gorm:"comment:Triggered At"`
+	Delivered   []string  `gorm:"type:jsonb;serializer:json;not null;default:'[]';comment:Delivered Channels"`
+	Completed   bool      `gorm:"not null;default:false;comment:Delivered To All Channels"`
+
+	IPRecord IPModel `gorm:"foreignKey:IpsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (AlertStateModel) TableName() string {
+	return "sender_score_alert_states"
+}
+
+type NotificationChannelModel struct {
+	ID      uint   `gorm:"primaryKey;comment:ID"`
+	GroupID uint   `gorm:"not null;uniqueIndex:idx_channel_target;comment:Group Internal ID"`
+	Type    string `gorm:"type:varchar(16);uniqueIndex:idx_channel_target;comment:Type"`
+	Target  string `gorm:"type:varchar(512);uniqueIndex:idx_channel_target;comment:Target"`
+
+	Group GroupModel `gorm:"foreignKey:GroupID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (NotificationChannelModel) TableName() string {
+	return "sender_score_notification_channels"
+}
+
+type IPEventModel struct {
+	ID        uint      `gorm:"primaryKey;comment:ID"`
+	IpsID     uint      `gorm:"not null;index:idx_ip_events_ip_created;comment:IPs ID"`
+	Field     string    `gorm:"type:varchar(32);comment:Field"`
+	OldValue  string    `gorm:"type:varchar(50);comment:Old Value"`
+	NewValue  string    `gorm:"type:varchar(50);comment:New Value"`
+	CreatedAt time.Time `gorm:"index:idx_ip_events_ip_created;comment:Created"`
+
+	IPRecord IPModel `gorm:"foreignKey:IpsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (IPEventModel) TableName() string {
+	return "sender_score_ip_events"
+}
@@ -0,0 +1,100 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type notificationChannelRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationChannelRepository(db *gorm.DB) domain.NotificationChannelRepository {
+	return &notificationChannelRepository{db: db}
+}
+
+// channelRow - канал вместе с внешним group_id группы
+type channelRow struct {
+	ID      uint
+	GroupID int
+	Type    string
+	Target  string
+}
+
+func (r *notificationChannelRepository) Create(ctx context.Context, channel *domain.NotificationChannel) error {
+	var group GroupModel
+	if err := r.db.WithContext(ctx).Where("group_id = ?", channel.GroupID).First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrGroupNotFound
+		}
+		return fmt.Errorf("failed to find group: %w", err)
+	}
+
+	model := &NotificationChannelModel{
+		GroupID: group.ID,
+		Type:    channel.Type,
+		Target:  channel.Target,
+	}
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(model)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create notification channel: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrChannelExists
+	}
+
+	channel.ID = model.ID
+	return nil
+}
+
+func (r *notificationChannelRepository) ListByGroupID(ctx context.Context, groupID int) ([]*domain.NotificationChannel, error) {
+	return r.ListByGroupIDs(ctx, []int{groupID})
+}
+
+func (r *notificationChannelRepository) ListByGroupIDs(ctx context.Context, groupIDs []int) ([]*domain.NotificationChannel, error) {
+	if len(groupIDs) == 0 {
+		return nil, nil
+	}
+
+	var rows []channelRow
+	if err := r.db.WithContext(ctx).
+		Table("sender_score_notification_channels").
+		Select("sender_score_notification_channels.id, sender_score_groups.group_id, sender_score_notification_channels.type, sender_score_notification_channels.target").
+		Joins("JOIN sender_score_groups ON sender_score_groups.id = sender_score_notification_channels.group_id").
+		Where("sender_score_groups.group_id IN ?", groupIDs).
+		Order("sender_score_notification_channels.id ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list notification channels: %w", err)
+	}
+
+	channels := make([]*domain.NotificationChannel, len(rows))
+	for i, row := range rows {
+		channels[i] = &domain.NotificationChannel{
+			ID:      row.ID,
+			GroupID: row.GroupID,
+			Type:    row.Type,
+			Target:  row.Target,
+		}
+	}
+
+	return channels, nil
+}
+
+func (r *notificationChannelRepository) Delete(ctx context.Context, groupID int, id uint) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND group_id IN (?)", id,
+			r.db.Model(&GroupModel{}).Select("id").Where("group_id = ?", groupID)).
+		Delete(&NotificationChannelModel{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete notification channel: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrChannelNotFound
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package data
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+const rawReportBatchSize = 100
+
+type rawReportRepository struct {
+	db *gorm.DB
+}
+
+func NewRawReportRepository(db *gorm.DB) domain.ReportArchive {
+	return &rawReportRepository{db: db}
+}
+
+func (r *rawReportRepository) Store(ctx context.Context, report *domain.RawReport) error {
+	body, err := compress(report.Body)
+	if err != nil {
+		return fmt.Errorf("failed to compress raw report: %w", err)
+	}
+
+	model := &RawReportModel{
+		IP:        report.IP,
+		Source:    report.Source,
+		FetchedAt: report.FetchedAt,
+		Body:      body,
+	}
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to store raw report: %w", err)
+	}
+	report.ID = model.ID
+	return nil
+}
+
+func (r *rawReportRepository) Each(ctx context.Context, filter domain.RawReportFilter, fn func(report *domain.RawReport) error) error {
+	query := r.db.WithContext(ctx).Model(&RawReportModel{})
+	if filter.IP != "" {
+		query = query.Where("ip = ?", filter.IP)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("fetched_at >= ?", filter.Since)
+	}
+
+	// Пагинация по ключу (fetched_at, id): FindInBatches листает по id и
+	// пропускает отчеты, если порядок получения не совпадает с порядком id
+	var last *RawReportModel
+	for {
+		page := query.Session(&gorm.Session{})
+		if last != nil {
+			page = page.Where("(fetched_at, id) > (?, ?)", last.FetchedAt, last.ID)
+		}
+
+		var models []RawReportModel
+		if err := page.Order("fetched_at ASC, id ASC").Limit(rawReportBatchSize).Find(&models).Error; err != nil {
+			return fmt.Errorf("failed to list raw reports: %w", err)
+		}
+
+		for _, model := range models {
+			body, err := decompress(model.Body)
+			if err != nil {
+				return fmt.Errorf("failed to decompress raw report %d: %w", model.ID, err)
+			}
+
+			if err := fn(&domain.RawReport{
+				ID:        model.ID,
+				IP:        model.IP,
+				Source:    model.Source,
+				FetchedAt: model.FetchedAt,
+				Body:      body,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if len(models) < rawReportBatchSize {
+			return nil
+		}
+		last = &models[len(models)-1]
+	}
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
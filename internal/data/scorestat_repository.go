@@ -6,6 +6,7 @@ import (
 
 	"git.emercury.dev/emercury/senderscore/api/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type scoreStatRepository struct {
@@ -18,7 +19,12 @@ func NewScoreStatRepository(db *gorm.DB) domain.ScoreStatRepository {
 
 func (r *scoreStatRepository) Create(ctx context.Context, stat *domain.ScoreStat) error {
 	model := toScoreStatModel(stat)
-	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "ips_id"}, {Name: "observed_at"}},
+			DoNothing: true,
+		}).
+		Create(model).Error; err != nil {
 		return fmt.Errorf("failed to create score stat: %w", err)
 	}
 	stat.ID = model.ID
@@ -27,7 +33,7 @@ func (r *scoreStatRepository) Create(ctx context.Context, stat *domain.ScoreStat
 
 func (r *scoreStatRepository) ListByIPID(ctx context.Context, ipID uint) ([]*domain.ScoreStat, error) {
 	var models []ScoreStatModel
-	if err := r.db.WithContext(ctx).Where("ips_id = ?", ipID).Find(&models).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("ips_id = ?", ipID).Order("date ASC, id ASC").Find(&models).Error; err != nil {
 		return nil, fmt.Errorf("failed to list score stats by IP: %w", err)
 	}
 
@@ -0,0 +1,31 @@
+package data
+
+import (
+	"context"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type unitOfWork struct {
+	db         *gorm.DB
+	thresholds domain.ScoreThresholds
+}
+
+// NewUnitOfWork creates a unit of work over the database. thresholds are
+// passed to the group repository used inside the transaction.
+func NewUnitOfWork(db *gorm.DB, thresholds domain.ScoreThresholds) domain.UnitOfWork {
+	return &unitOfWork{db: db, thresholds: thresholds}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(repos domain.Repositories) error) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(domain.Repositories{
+			Groups:     NewGroupRepository(tx, u.thresholds),
+			IPs:        NewIPRepository(tx),
+			History:    NewHistoryRepository(tx),
+			ScoreStats: NewScoreStatRepository(tx),
+			Events:     NewIPEventRepository(tx),
+		})
+	})
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// MaxCIDRAddresses ограничивает число IP, которое можно добавить одним диапазоном.
+const MaxCIDRAddresses = 256
+
+// CanonicalIP разбирает IPv4 или IPv6 адрес и возвращает его каноническую
+// запись. IPv4, отображенный в IPv6 (::ffff:1.2.3.4), приводится к IPv4.
+func CanonicalIP(address string) (string, error) {
+	addr, err := netip.ParseAddr(address)
+	if err != nil || addr.Zone() != "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidIP, address)
+	}
+	return addr.Unmap().String(), nil
+}
+
+// ExpandCIDR возвращает адреса диапазона в канонической записи, как у
+// CanonicalIP. Для IPv4 сетей крупнее /31 адреса сети и broadcast пропускаются.
+func ExpandCIDR(cidr string) ([]string, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidIP, cidr)
+	}
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	prefix = prefix.Masked()
+
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits > 16 || 1<<hostBits > MaxCIDRAddresses {
+		return nil, fmt.Errorf("%w: %s, at most %d addresses are allowed", ErrCIDRTooLarge, prefix, MaxCIDRAddresses)
+	}
+
+	skipEdges := prefix.Addr().Is4() && hostBits > 1
+	total := 1 << hostBits
+
+	addresses := make([]string, 0, total)
+	addr := prefix.Addr()
+	for i := 0; i < total; i++ {
+		if !skipEdges || (i != 0 && i != total-1) {
+			addresses = append(addresses, addr.String())
+		}
+		addr = addr.Next()
+	}
+
+	return addresses, nil
+}
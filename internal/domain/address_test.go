@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCanonicalIP(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+		wantErr bool
+	}{
+		{address: "192.0.2.10", want: "192.0.2.10"},
+		{address: "2001:DB8:0:0::1", want: "2001:db8::1"},
+		{address: "::ffff:192.0.2.10", want: "192.0.2.10"},
+		{address: "192.000.002.010", wantErr: true},
+		{address: " 192.0.2.10", wantErr: true},
+		{address: "192.0.2.10\n", wantErr: true},
+		{address: "fe80::1%eth0", wantErr: true},
+		{address: "192.0.2.0/24", wantErr: true},
+		{address: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := CanonicalIP(tt.address)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidIP) {
+				t.Errorf("CanonicalIP(%q) error = %v, want ErrInvalidIP", tt.address, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("CanonicalIP(%q) = %q, %v; want %q", tt.address, got, err, tt.want)
+		}
+	}
+}
+
+func TestExpandCIDR(t *testing.T) {
+	tests := []struct {
+		cidr    string
+		want    []string
+		wantErr error
+	}{
+		{cidr: "192.0.2.0/30", want: []string{"192.0.2.1", "192.0.2.2"}},
+		{cidr: "192.0.2.5/30", want: []string{"192.0.2.5", "192.0.2.6"}},
+		{cidr: "192.0.2.0/31", want: []string{"192.0.2.0", "192.0.2.1"}},
+		{cidr: "192.0.2.7/32", want: []string{"192.0.2.7"}},
+		{cidr: "2001:db8::/126", want: []string{"2001:db8::", "2001:db8::1", "2001:db8::2", "2001:db8::3"}},
+		{cidr: "2001:DB8::1/128", want: []string{"2001:db8::1"}},
+		{cidr: "::ffff:192.0.2.0/126", want: []string{"192.0.2.1", "192.0.2.2"}},
+		{cidr: "::ffff:192.0.2.9/128", want: []string{"192.0.2.9"}},
+		{cidr: "192.0.2.0/23", wantErr: ErrCIDRTooLarge},
+		{cidr: "2001:db8::/64", wantErr: ErrCIDRTooLarge},
+		{cidr: "192.000.002.000/30", wantErr: ErrInvalidIP},
+		{cidr: " 192.0.2.0/30", wantErr: ErrInvalidIP},
+		{cidr: "192.0.2.0/33", wantErr: ErrInvalidIP},
+		{cidr: "192.0.2.1", wantErr: ErrInvalidIP},
+	}
+
+	for _, tt := range tests {
+		got, err := ExpandCIDR(tt.cidr)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ExpandCIDR(%q) error = %v, want %v", tt.cidr, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ExpandCIDR(%q) unexpected error: %v", tt.cidr, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ExpandCIDR(%q) = %v, want %v", tt.cidr, got, tt.want)
+		}
+	}
+
+	// Самый большой допустимый диапазон
+	got, err := ExpandCIDR("192.0.2.0/24")
+	if err != nil || len(got) != 254 || got[0] != "192.0.2.1" || got[253] != "192.0.2.254" {
+		t.Errorf("ExpandCIDR(192.0.2.0/24) = %d addresses, %v; want 254 from 192.0.2.1 to 192.0.2.254", len(got), err)
+	}
+}
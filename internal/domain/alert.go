@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	AlertRuleScoreBelow  = "score_below"
+	AlertRuleScoreDrop   = "score_drop"
+	AlertRuleSpamTraps   = "spam_traps"
+	AlertRuleBlocklisted = "blocklisted"
+)
+
+// AlertRules описывает условия срабатывания алертов. Нулевое значение
+// порога или false отключает соответствующее правило.
+type AlertRules struct {
+	ScoreBelow  int
+	ScoreDrop   int
+	SpamTraps   bool
+	Blocklisted bool
+}
+
+type Alert struct {
+	Rule               string
+	IP                 string
+	GroupIDs           []int
+	Score              int
+	PreviousScore      int
+	SpamTrap           int
+	Blocklists         string
+	PreviousBlocklists string
+	Message            string
+	TriggeredAt        time.Time
+}
+
+// Notifier доставляет алерт во все свои каналы, кроме тех, чьи ключи уже
+// есть в delivered, и добавляет в delivered ключи каналов, принявших алерт.
+// Ключ канала - тип и адрес, например "webhook:https://hooks.example.com".
+type Notifier interface {
+	Notify(ctx context.Context, alert *Alert, delivered map[string]bool) error
+}
+
+// AlertState - активный алерт по правилу для одного IP. Delivered содержит
+// ключи каналов, уже получивших алерт; Completed означает, что алерт
+// доставлен во все каналы и повторно не отправляется.
+type AlertState struct {
+	Rule        string
+	TriggeredAt time.Time
+	Delivered   []string
+	Completed   bool
+}
+
+// AlertStateRepository хранит активные алерты, чтобы одно и то же условие
+// не отправлялось повторно при каждом обновлении IP.
+type AlertStateRepository interface {
+	// Activate возвращает состояние активного правила, создавая его при первом срабатывании.
+	Activate(ctx context.Context, ipID uint, rule string) (*AlertState, error)
+	SaveDelivery(ctx context.Context, ipID uint, state *AlertState) error
+	Resolve(ctx context.Context, ipID uint, rule string) error
+}
+
+const (
+	ChannelTypeChat  = "chat"
+	ChannelTypeEmail = "email"
+)
+
+// NotificationChannel - канал доставки алертов для одной группы. Target -
+// URL входящего вебхука для chat или адрес получателя для email.
+type NotificationChannel struct {
+	ID      uint
+	GroupID int
+	Type    string
+	Target  string
+}
+
+// ChannelSender доставляет алерт в канал одного типа.
+type ChannelSender interface {
+	Send(ctx context.Context, target string, alert *Alert) error
+}
+
+type NotificationChannelRepository interface {
+	Create(ctx context.Context, channel *NotificationChannel) error
+	ListByGroupID(ctx context.Context, groupID int) ([]*NotificationChannel, error)
+	ListByGroupIDs(ctx context.Context, groupIDs []int) ([]*NotificationChannel, error)
+	Delete(ctx context.Context, groupID int, id uint) error
+}
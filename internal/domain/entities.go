@@ -1,6 +1,11 @@
 package domain
 
-import "time"
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Group struct {
 	ID            uint
@@ -8,8 +13,53 @@ type Group struct {
 	GroupName     string
 	SpamTrapCount int
 	IPsCount      int
+
+	AvgScore           float64
+	MinScore           int
+	WeightedScore      float64
+	LowScoreCount      int
+	CriticalScoreCount int
+	BlocklistedCount   int
+	AvgComplaintRate   float64
+}
+
+// ScoreThresholds задает границы, ниже которых IP учитывается в
+// LowScoreCount и CriticalScoreCount группы.
+type ScoreThresholds struct {
+	Low      int
+	Critical int
+}
+
+const (
+	GroupSortGroupID   = "group_id"
+	GroupSortName      = "name"
+	GroupSortSpamTraps = "spam_trap_count"
+	GroupSortIPsCount  = "ips_count"
+)
+
+type GroupFilter struct {
+	Search       string
+	MinSpamTraps *int
+	MaxSpamTraps *int
+	MinIPs       *int
+	SortBy       string
+	SortDesc     bool
 }
 
+func IsValidGroupSort(sortBy string) bool {
+	switch sortBy {
+	case GroupSortGroupID, GroupSortName, GroupSortSpamTraps, GroupSortIPsCount:
+		return true
+	}
+	return false
+}
+
+const (
+	IPStatusPending = "pending"
+	IPStatusOK      = "ok"
+	IPStatusNoData  = "no_data"
+)
+
 type IP struct {
 	ID         uint
 	IP         string
@@ -17,8 +67,88 @@ type IP struct {
 	SpamTrap   int
 	Blocklists string
 	Complaints string
+	Status     string
 	UpdatedAt  time.Time
 	GroupIDs   []int
+
+	BlocklistCount int
+	ComplaintRate  float64
+}
+
+// IsBlocklisted сообщает, есть ли IP в blocklists. Нечисловой текст отчета
+// (BlocklistCountUnknown) тоже считается попаданием.
+func (ip *IP) IsBlocklisted() bool {
+	return ip.BlocklistCount != 0
+}
+
+// GroupLink связывает IP с группой по ее внешнему group_id.
+type GroupLink struct {
+	IPID    uint
+	GroupID int
+}
+
+// BlocklistCountUnknown хранится, когда текст blocklists есть, но числа в нем
+// нет (например, "Listed").
+const BlocklistCountUnknown = -1
+
+var reLeadingNumber = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?`)
+
+// ParseBlocklistCount извлекает число blocklists из текста отчета ("0", "2").
+// Пустой текст означает 0. ok=false, если текст не начинается с числа.
+func ParseBlocklistCount(text string) (int, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, true
+	}
+	match := reLeadingNumber.FindString(text)
+	if match == "" {
+		return 0, false
+	}
+	count, err := strconv.Atoi(strings.SplitN(match, ".", 2)[0])
+	if err != nil {
+		return 0, false
+	}
+	return count, true
+}
+
+// BlocklistCountOf возвращает число blocklists из текста отчета или
+// BlocklistCountUnknown, если текст не начинается с числа.
+func BlocklistCountOf(text string) int {
+	count, ok := ParseBlocklistCount(text)
+	if !ok {
+		return BlocklistCountUnknown
+	}
+	return count
+}
+
+// ParseComplaintRate извлекает процент жалоб из текста отчета ("0.41%" -> 0.41).
+// Пустой текст означает 0. ok=false, если текст не начинается с числа.
+func ParseComplaintRate(text string) (float64, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, true
+	}
+	match := reLeadingNumber.FindString(text)
+	if match == "" {
+		return 0, false
+	}
+	rate, err := strconv.ParseFloat(match, 64)
+	if err != nil {
+		return 0, false
+	}
+	return rate, true
+}
+
+type IPFilter struct {
+	GroupID      int
+	Search       string
+	Status       string
+	MinScore     *int
+	MaxScore     *int
+	HasSpamTraps *bool
+
+	Blocklisted      *bool
+	MinComplaintRate *float64
 }
 
 type History struct {
@@ -30,10 +160,90 @@ type History struct {
 	Time     time.Time
 }
 
+// GroupHistoryPoint агрегирует историю всех IP группы за один день.
+type GroupHistoryPoint struct {
+	Date     time.Time
+	AvgScore float64
+	MinScore int
+	Volume   int
+	SpamTrap int
+	IPsCount int
+}
+
+type RawReport struct {
+	ID        uint
+	IP        string
+	Source    string
+	FetchedAt time.Time
+	Body      []byte
+}
+
+type RawReportFilter struct {
+	IP    string
+	Since time.Time
+}
+
+const (
+	ScoreResultGood   = 1
+	ScoreResultFair   = 2
+	ScoreResultPoor   = 3
+	ScoreResultNoData = 4
+)
+
+// ScoreStat - результат одной проверки IP. ObservedAt - время получения
+// отчета; для одного IP оно уникально, поэтому повторное применение отчета
+// из архива не создает вторую запись.
 type ScoreStat struct {
-	ID     uint
-	IPsID  uint
-	Score  int
-	Result int
-	Date   time.Time
+	ID         uint
+	IPsID      uint
+	Score      int
+	Result     int
+	Date       time.Time
+	ObservedAt time.Time
+}
+
+// ScoreResultFor classifies a sender score the way senderscore.org colours
+// it: 80 and above is good, 70-79 needs attention, below 70 is poor.
+func ScoreResultFor(score int) int {
+	switch {
+	case score >= 80:
+		return ScoreResultGood
+	case score >= 70:
+		return ScoreResultFair
+	default:
+		return ScoreResultPoor
+	}
+}
+
+func ScoreResultName(result int) string {
+	switch result {
+	case ScoreResultGood:
+		return "good"
+	case ScoreResultFair:
+		return "fair"
+	case ScoreResultPoor:
+		return "poor"
+	case ScoreResultNoData:
+		return "no_data"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	IPEventFieldScore      = "score"
+	IPEventFieldSpamTrap   = "spam_trap"
+	IPEventFieldBlocklists = "blocklists"
+	IPEventFieldComplaints = "complaints"
+)
+
+// IPEvent - запись журнала изменений одного поля IP. Для только что
+// созданного IP OldValue пустой.
+type IPEvent struct {
+	ID        uint
+	IPsID     uint
+	Field     string
+	OldValue  string
+	NewValue  string
+	CreatedAt time.Time
 }
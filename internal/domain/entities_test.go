@@ -0,0 +1,86 @@
+package domain
+
+import "testing"
+
+func TestParseBlocklistCount(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   int
+		wantOK bool
+	}{
+		{text: "", want: 0, wantOK: true},
+		{text: "   ", want: 0, wantOK: true},
+		{text: "0", want: 0, wantOK: true},
+		{text: "2", want: 2, wantOK: true},
+		{text: " 3 ", want: 3, wantOK: true},
+		{text: "4 lists", want: 4, wantOK: true},
+		{text: "1.0", want: 1, wantOK: true},
+		{text: "Listed", want: 0, wantOK: false},
+		{text: "-1", want: 0, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseBlocklistCount(tt.text)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseBlocklistCount(%q) = %d, %t; want %d, %t", tt.text, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestBlocklistCountOf(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{text: "", want: 0},
+		{text: "2", want: 2},
+		{text: "Listed", want: BlocklistCountUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := BlocklistCountOf(tt.text); got != tt.want {
+			t.Errorf("BlocklistCountOf(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestIPIsBlocklisted(t *testing.T) {
+	tests := []struct {
+		count int
+		want  bool
+	}{
+		{count: 0, want: false},
+		{count: 2, want: true},
+		{count: BlocklistCountUnknown, want: true},
+	}
+
+	for _, tt := range tests {
+		ip := &IP{BlocklistCount: tt.count}
+		if got := ip.IsBlocklisted(); got != tt.want {
+			t.Errorf("IsBlocklisted() with count %d = %t, want %t", tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestParseComplaintRate(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   float64
+		wantOK bool
+	}{
+		{text: "", want: 0, wantOK: true},
+		{text: "0.00%", want: 0, wantOK: true},
+		{text: "0.41%", want: 0.41, wantOK: true},
+		{text: " 1.5 % ", want: 1.5, wantOK: true},
+		{text: "3", want: 3, wantOK: true},
+		{text: "N/A", want: 0, wantOK: false},
+		{text: "%", want: 0, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseComplaintRate(tt.text)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseComplaintRate(%q) = %v, %t; want %v, %t", tt.text, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
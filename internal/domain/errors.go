@@ -6,7 +6,15 @@ var (
 	ErrGroupNotFound      = errors.New("group not found")
 	ErrGroupAlreadyExists = errors.New("group already exists")
 	ErrInvalidGroupID     = errors.New("invalid group id")
+	ErrInvalidGroupSort   = errors.New("invalid group sort")
 	ErrIPNotFound         = errors.New("ip not found")
 	ErrIPAlreadyExists    = errors.New("ip already exists")
+	ErrIPNotInGroup       = errors.New("ip is not in group")
+	ErrInvalidIP          = errors.New("invalid ip address")
+	ErrCIDRTooLarge       = errors.New("cidr range is too large")
 	ErrInvalidDateFormat  = errors.New("invalid date format")
+	ErrInvalidChannel     = errors.New("invalid notification channel")
+	ErrChannelNotFound    = errors.New("notification channel not found")
+	ErrChannelExists      = errors.New("notification channel already exists")
+	ErrNoChannels         = errors.New("no notification channels for alert")
 )
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type ScoreReport struct {
+	IP            string
+	Source        string
+	NoData        bool
+	Score         int
+	SpamTrap      int
+	Blocklists    string
+	Complaints    string
+	History       []HistoryPoint
+	MissingFields []string
+	// FetchedAt is when the page was downloaded, with millisecond precision
+	// so that it matches the archived copy. Zero when unknown.
+	FetchedAt time.Time
+
+	BlocklistCount int
+	ComplaintRate  float64
+}
+
+type HistoryPoint struct {
+	Date     time.Time
+	Score    int
+	Volume   int
+	SpamTrap int
+}
+
+type ScoreProvider interface {
+	Name() string
+	Fetch(ctx context.Context, ip string) (*ScoreReport, error)
+}
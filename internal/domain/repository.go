@@ -1,12 +1,15 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type GroupRepository interface {
 	Create(ctx context.Context, group *Group) error
 	GetByID(ctx context.Context, id uint) (*Group, error)
 	GetByGroupID(ctx context.Context, groupID int) (*Group, error)
-	List(ctx context.Context, offset, limit int) ([]*Group, int64, error)
+	List(ctx context.Context, filter GroupFilter, offset, limit int) ([]*Group, int64, error)
 	Update(ctx context.Context, group *Group) error
 	Delete(ctx context.Context, groupID int) error
 	UpdateCounters(ctx context.Context, groupID int) error
@@ -15,13 +18,22 @@ type GroupRepository interface {
 
 type IPRepository interface {
 	Create(ctx context.Context, ip *IP) error
+	CreateBatch(ctx context.Context, ips []*IP) error
 	GetByID(ctx context.Context, id uint) (*IP, error)
 	GetByIP(ctx context.Context, ipAddress string) (*IP, error)
+	ListByAddresses(ctx context.Context, addresses []string) ([]*IP, error)
 	GetOldestIP(ctx context.Context) (*IP, error)
+	ClaimStale(ctx context.Context, limit int, lease time.Duration) ([]*IP, error)
+	// Claim захватывает IP на время lease и возвращает false, если IP уже занят.
+	Claim(ctx context.Context, id uint, lease time.Duration) (bool, error)
+	ReleaseClaim(ctx context.Context, id uint) error
+	ListAll(ctx context.Context) ([]*IP, error)
+	List(ctx context.Context, filter IPFilter, offset, limit int) ([]*IP, int64, error)
 	ListByGroupID(ctx context.Context, groupID int) ([]*IP, error)
 	Update(ctx context.Context, ip *IP) error
 	Delete(ctx context.Context, id uint) error
 	AddToGroup(ctx context.Context, ipID uint, groupID int) error
+	AddToGroups(ctx context.Context, links []GroupLink) error
 	RemoveFromGroup(ctx context.Context, ipID uint, groupID int) error
 	IsIPInOtherGroups(ctx context.Context, ipID uint, excludeGroupID int) (bool, error)
 }
@@ -30,7 +42,10 @@ type HistoryRepository interface {
 	Create(ctx context.Context, history *History) error
 	GetByIPAndDate(ctx context.Context, ipID uint, date string) (*History, error)
 	ListByIPID(ctx context.Context, ipID uint) ([]*History, error)
+	ListByIPIDAndRange(ctx context.Context, ipID uint, from, to time.Time) ([]*History, error)
+	AggregateByGroup(ctx context.Context, groupID uint, from, to time.Time) ([]*GroupHistoryPoint, error)
 	Update(ctx context.Context, history *History) error
+	Upsert(ctx context.Context, histories []*History) (added int, err error)
 	DeleteByIPID(ctx context.Context, ipID uint) error
 }
 
@@ -39,3 +54,28 @@ type ScoreStatRepository interface {
 	ListByIPID(ctx context.Context, ipID uint) ([]*ScoreStat, error)
 	DeleteByIPID(ctx context.Context, ipID uint) error
 }
+
+type IPEventRepository interface {
+	Create(ctx context.Context, events []*IPEvent) error
+	ListByIPID(ctx context.Context, ipID uint, field string) ([]*IPEvent, error)
+}
+
+type ReportArchive interface {
+	Store(ctx context.Context, report *RawReport) error
+	Each(ctx context.Context, filter RawReportFilter, fn func(report *RawReport) error) error
+}
+
+// Repositories are the repositories taking part in one unit of work.
+type Repositories struct {
+	Groups     GroupRepository
+	IPs        IPRepository
+	History    HistoryRepository
+	ScoreStats ScoreStatRepository
+	Events     IPEventRepository
+}
+
+// UnitOfWork runs fn with repositories bound to a single transaction, which
+// is committed when fn returns nil and rolled back otherwise.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos Repositories) error) error
+}
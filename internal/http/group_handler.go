@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"math"
 	"net/http"
 	"strconv"
@@ -28,13 +29,25 @@ func (h *GroupHandler) ListGroups(c *gin.Context) {
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 	withIPs := c.DefaultQuery("with_ips", "false") == "true"
 
+	filter, ok := parseGroupFilter(c)
+	if !ok {
+		return
+	}
+
 	pagination := usecase.PaginationDTO{
 		Page:     page,
 		PageSize: pageSize,
-	}
+	}.Normalize()
 
-	groups, total, err := h.groupUC.ListGroups(c.Request.Context(), pagination, withIPs)
+	groups, total, err := h.groupUC.ListGroups(c.Request.Context(), filter, pagination, withIPs)
 	if err != nil {
+		if err == domain.ErrInvalidGroupSort {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_sort",
+				Message: "Invalid 'sort', expected one of: group_id, name, spam_trap_count, ips_count",
+			})
+			return
+		}
 		logrus.WithError(err).Error("Failed to list groups")
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
@@ -44,12 +57,12 @@ func (h *GroupHandler) ListGroups(c *gin.Context) {
 	}
 
 	responses := toGroupResponses(groups)
-	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
+	totalPages := int(math.Ceil(float64(total) / float64(pagination.PageSize)))
 
 	c.JSON(http.StatusOK, PaginatedResponse{
 		Data:       responses,
-		Page:       page,
-		PageSize:   pageSize,
+		Page:       pagination.Page,
+		PageSize:   pagination.PageSize,
 		TotalItems: total,
 		TotalPages: totalPages,
 	})
@@ -123,16 +136,48 @@ func (h *GroupHandler) GetGroupByGroupID(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-func (h *GroupHandler) CreateGroup(c *gin.Context) {
-	var req CreateGroupRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+func (h *GroupHandler) GetGroupHistory(c *gin.Context) {
+	groupIDParam := c.Param("group_id")
+	groupID, err := strconv.Atoi(groupIDParam)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_request",
-			Message: err.Error(),
+			Error:   "invalid_group_id",
+			Message: "Invalid group_id format",
+		})
+		return
+	}
+
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	history, err := h.groupUC.GetGroupHistory(c.Request.Context(), groupID, from, to)
+	if err != nil {
+		if err == domain.ErrGroupNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Group not found",
+			})
+			return
+		}
+		logrus.WithError(err).WithField("group_id", groupID).Error("Failed to get group history")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve group history",
 		})
 		return
 	}
 
+	c.JSON(http.StatusOK, toGroupHistoryPointResponses(history))
+}
+
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	var req CreateGroupRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
 	dto := toCreateGroupDTO(req)
 	group, err := h.groupUC.CreateGroup(c.Request.Context(), dto)
 	if err != nil {
@@ -214,22 +259,43 @@ func (h *GroupHandler) DeleteGroup(c *gin.Context) {
 
 func (h *GroupHandler) AddIP(c *gin.Context) {
 	var req AddIPRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_request",
-			Message: err.Error(),
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	if req.IP == "" && req.GroupName == "" {
+	if req.IP == "" && req.CIDR == "" && req.GroupName == "" {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "invalid_request",
-			Message: "Either 'ip' or 'group_name' must be provided",
+			Message: "Either 'ip', 'cidr' or 'group_name' must be provided",
 		})
 		return
 	}
 
+	if req.CIDR != "" {
+		result, err := h.ipUC.AddIPs(c.Request.Context(), []usecase.AddIPDTO{toAddIPDTO(req)})
+		if err == nil && result.IPsInvalid > 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_ip",
+				Message: result.Items[0].Reason,
+			})
+			return
+		}
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"group_id": req.GroupID,
+				"cidr":     req.CIDR,
+			}).Error("Failed to add CIDR range")
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to add IP addresses",
+			})
+			return
+		}
+
+		c.JSON(http.StatusCreated, toAddIPsResponse(result))
+		return
+	}
+
 	if req.IP == "" && req.GroupName != "" {
 		if err := h.groupUC.UpdateGroupName(c.Request.Context(), req.GroupID, req.GroupName); err != nil {
 			if err == domain.ErrGroupNotFound {
@@ -264,6 +330,9 @@ func (h *GroupHandler) AddIP(c *gin.Context) {
 	dto := toAddIPDTO(req)
 	ip, err := h.ipUC.AddIP(c.Request.Context(), dto)
 	if err != nil {
+		if writeAddressError(c, err) {
+			return
+		}
 		logrus.WithError(err).WithFields(logrus.Fields{
 			"group_id":   req.GroupID,
 			"group_name": req.GroupName,
@@ -282,11 +351,7 @@ func (h *GroupHandler) AddIP(c *gin.Context) {
 
 func (h *GroupHandler) AddIPs(c *gin.Context) {
 	var req AddIPsRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_request",
-			Message: err.Error(),
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -294,10 +359,14 @@ func (h *GroupHandler) AddIPs(c *gin.Context) {
 	result, err := h.ipUC.AddIPs(c.Request.Context(), dtos)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to add IPs in batch")
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Failed to add IP addresses",
-		})
+		if result == nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to add IP addresses",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, toAddIPsResponse(result))
 		return
 	}
 
@@ -307,17 +376,23 @@ func (h *GroupHandler) AddIPs(c *gin.Context) {
 
 func (h *GroupHandler) SubmitScore(c *gin.Context) {
 	var req SubmitScoreRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_request",
-			Message: err.Error(),
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	dto := toSubmitScoreDTO(req)
 	result, err := h.ipUC.SubmitScore(c.Request.Context(), dto)
 	if err != nil {
+		if writeAddressError(c, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidDateFormat) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_date",
+				Message: err.Error(),
+			})
+			return
+		}
 		logrus.WithError(err).Error("Failed to submit score")
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
@@ -351,3 +426,198 @@ func (h *GroupHandler) GetOldestIP(c *gin.Context) {
 	response := toIPResponse(ip)
 	c.JSON(http.StatusOK, response)
 }
+
+// parseGroupFilter reads the group list filters and sorting from the query
+// string. It writes a 400 response and returns false when one of them is
+// malformed.
+func parseGroupFilter(c *gin.Context) (usecase.GroupFilterDTO, bool) {
+	filter := usecase.GroupFilterDTO{
+		Search: c.Query("search"),
+		SortBy: c.Query("sort"),
+	}
+
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		filter.SortDesc = true
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_sort",
+			Message: "Invalid 'order', expected asc or desc",
+		})
+		return usecase.GroupFilterDTO{}, false
+	}
+
+	for _, param := range []struct {
+		name   string
+		target **int
+	}{
+		{name: "min_spam_traps", target: &filter.MinSpamTraps},
+		{name: "max_spam_traps", target: &filter.MaxSpamTraps},
+		{name: "min_ips", target: &filter.MinIPs},
+	} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_filter",
+				Message: "Invalid '" + param.name + "' filter",
+			})
+			return usecase.GroupFilterDTO{}, false
+		}
+		*param.target = &n
+	}
+
+	return filter, true
+}
+
+func (h *GroupHandler) ListChannels(c *gin.Context) {
+	groupIDParam := c.Param("group_id")
+	groupID, err := strconv.Atoi(groupIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_group_id",
+			Message: "Invalid group_id format",
+		})
+		return
+	}
+
+	channels, err := h.groupUC.ListChannels(c.Request.Context(), groupID)
+	if err != nil {
+		if err == domain.ErrGroupNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Group not found",
+			})
+			return
+		}
+		logrus.WithError(err).WithField("group_id", groupID).Error("Failed to list notification channels")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve notification channels",
+		})
+		return
+	}
+
+	responses := make([]ChannelResponse, len(channels))
+	for i, channel := range channels {
+		responses[i] = toChannelResponse(channel)
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+func (h *GroupHandler) AddChannel(c *gin.Context) {
+	groupIDParam := c.Param("group_id")
+	groupID, err := strconv.Atoi(groupIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_group_id",
+			Message: "Invalid group_id format",
+		})
+		return
+	}
+
+	var req CreateChannelRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	channel, err := h.groupUC.AddChannel(c.Request.Context(), usecase.NotificationChannelDTO{
+		GroupID: groupID,
+		Type:    req.Type,
+		Target:  req.Target,
+	})
+	if err != nil {
+		switch {
+		case err == domain.ErrGroupNotFound:
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Group not found",
+			})
+		case err == domain.ErrChannelExists:
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "already_exists",
+				Message: "Notification channel already exists",
+			})
+		case errors.Is(err, domain.ErrInvalidChannel):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_channel",
+				Message: err.Error(),
+			})
+		default:
+			logrus.WithError(err).WithField("group_id", groupID).Error("Failed to add notification channel")
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to add notification channel",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, toChannelResponse(channel))
+}
+
+func (h *GroupHandler) DeleteChannel(c *gin.Context) {
+	groupIDParam := c.Param("group_id")
+	groupID, err := strconv.Atoi(groupIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_group_id",
+			Message: "Invalid group_id format",
+		})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid channel ID format",
+		})
+		return
+	}
+
+	if err := h.groupUC.DeleteChannel(c.Request.Context(), groupID, uint(id)); err != nil {
+		switch err {
+		case domain.ErrGroupNotFound:
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Group not found",
+			})
+		case domain.ErrChannelNotFound:
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Notification channel not found",
+			})
+		default:
+			logrus.WithError(err).WithField("group_id", groupID).Error("Failed to delete notification channel")
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to delete notification channel",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notification channel deleted successfully",
+	})
+}
+
+// writeAddressError writes a 400 response for an invalid IP address or CIDR
+// range and reports whether it did.
+func writeAddressError(c *gin.Context, err error) bool {
+	if !errors.Is(err, domain.ErrInvalidIP) && !errors.Is(err, domain.ErrCIDRTooLarge) {
+		return false
+	}
+
+	c.JSON(http.StatusBadRequest, ErrorResponse{
+		Error:   "invalid_ip",
+		Message: err.Error(),
+	})
+	return true
+}
@@ -0,0 +1,218 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeIPUseCase запоминает аргументы вызовов, которые дошли до usecase.
+type fakeIPUseCase struct {
+	usecase.IPUseCase
+	submitted  []usecase.SubmitScoreDTO
+	filter     *usecase.IPFilterDTO
+	from, to   time.Time
+	historyIPs []string
+}
+
+func (f *fakeIPUseCase) SubmitScore(ctx context.Context, dto usecase.SubmitScoreDTO) (*usecase.SubmitScoreResultDTO, error) {
+	f.submitted = append(f.submitted, dto)
+	return &usecase.SubmitScoreResultDTO{Success: true}, nil
+}
+
+func (f *fakeIPUseCase) ListIPs(ctx context.Context, filter usecase.IPFilterDTO, pagination usecase.PaginationDTO) ([]*usecase.IPDTO, int64, error) {
+	f.filter = &filter
+	return nil, 0, nil
+}
+
+func (f *fakeIPUseCase) GetHistory(ctx context.Context, ipAddress string, from, to time.Time) ([]usecase.HistoryEntryDTO, error) {
+	f.historyIPs = append(f.historyIPs, ipAddress)
+	f.from, f.to = from, to
+	return nil, nil
+}
+
+func newTestRouter(ipUC usecase.IPUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterRoutes(router, NewGroupHandler(nil, ipUC), NewIPHandler(ipUC), func(c *gin.Context) { c.Next() })
+	return router
+}
+
+func serve(router *gin.Engine, method, target, body string) (*httptest.ResponseRecorder, ErrorResponse) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func TestSubmitScoreValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantError   string
+		wantDetails []string
+	}{
+		{
+			name:        "missing fields",
+			body:        `{"history":[{"date":"15.02.2025"}]}`,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   "invalid_request",
+			wantDetails: []string{"ip", "score", "history[0].score", "history[0].volume"},
+		},
+		{
+			name:        "empty history",
+			body:        `{"ip":"192.0.2.10","score":90,"history":[]}`,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   "invalid_request",
+			wantDetails: []string{"history"},
+		},
+		{
+			name:        "bad date format",
+			body:        `{"ip":"192.0.2.10","score":90,"history":[{"date":"2025-02-15","score":90,"volume":10}]}`,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   "invalid_request",
+			wantDetails: []string{"history[0].date"},
+		},
+		{
+			name:        "invalid IP",
+			body:        `{"ip":"192.0.2.300","score":90,"history":[{"date":"15.02.2025","score":90,"volume":10}]}`,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   "invalid_request",
+			wantDetails: []string{"ip"},
+		},
+		{
+			name:        "score out of range",
+			body:        `{"ip":"192.0.2.10","score":101,"history":[{"date":"15.02.2025","score":-1,"volume":-5}]}`,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   "invalid_request",
+			wantDetails: []string{"score", "history[0].score", "history[0].volume"},
+		},
+		{
+			name:       "malformed JSON",
+			body:       `{"ip":`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ipUC := &fakeIPUseCase{}
+			w, resp := serve(newTestRouter(ipUC), http.MethodPost, "/api/v1/scores/submit", tt.body)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+			if len(ipUC.submitted) != 0 {
+				t.Errorf("rejected request reached the use case: %+v", ipUC.submitted)
+			}
+			for _, field := range tt.wantDetails {
+				if _, ok := resp.Details[field]; !ok {
+					t.Errorf("details = %v, want an entry for %q", resp.Details, field)
+				}
+			}
+		})
+	}
+}
+
+func TestSubmitScoreKeepsZeroValues(t *testing.T) {
+	ipUC := &fakeIPUseCase{}
+	body := `{"ip":"192.0.2.10","score":0,"history":[{"date":"15.02.2025","score":0,"volume":0}]}`
+	if w, _ := serve(newTestRouter(ipUC), http.MethodPost, "/api/v1/scores/submit", body); w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body)
+	}
+
+	if len(ipUC.submitted) != 1 {
+		t.Fatalf("SubmitScore called %d times, want 1", len(ipUC.submitted))
+	}
+	dto := ipUC.submitted[0]
+	if dto.Score != 0 || len(dto.History) != 1 || dto.History[0].Score != 0 || dto.History[0].Volume != 0 {
+		t.Errorf("submitted %+v, want zero score and volume", dto)
+	}
+}
+
+func TestListIPsFilter(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantStatus int
+	}{
+		{query: "", wantStatus: http.StatusOK},
+		{query: "?group_id=42&min_score=0&max_score=80&blocklisted=true&min_complaint_rate=0.5&has_spam_traps=false", wantStatus: http.StatusOK},
+		{query: "?group_id=abc", wantStatus: http.StatusBadRequest},
+		{query: "?min_score=high", wantStatus: http.StatusBadRequest},
+		{query: "?blocklisted=maybe", wantStatus: http.StatusBadRequest},
+		{query: "?min_complaint_rate=1%25", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		ipUC := &fakeIPUseCase{}
+		w, resp := serve(newTestRouter(ipUC), http.MethodGet, "/api/v1/ips"+tt.query, "")
+		if w.Code != tt.wantStatus {
+			t.Errorf("GET /ips%s status = %d, want %d: %s", tt.query, w.Code, tt.wantStatus, w.Body)
+			continue
+		}
+		if tt.wantStatus == http.StatusBadRequest && (resp.Error != "invalid_filter" || ipUC.filter != nil) {
+			t.Errorf("GET /ips%s = %+v, want invalid_filter without calling the use case", tt.query, resp)
+		}
+	}
+
+	ipUC := &fakeIPUseCase{}
+	serve(newTestRouter(ipUC), http.MethodGet, "/api/v1/ips"+tests[1].query, "")
+	f := ipUC.filter
+	if f == nil || f.GroupID != 42 || f.MinScore == nil || *f.MinScore != 0 || f.MaxScore == nil || *f.MaxScore != 80 ||
+		f.Blocklisted == nil || !*f.Blocklisted || f.MinComplaintRate == nil || *f.MinComplaintRate != 0.5 ||
+		f.HasSpamTraps == nil || *f.HasSpamTraps {
+		t.Errorf("filter = %+v, does not match the query", f)
+	}
+}
+
+func TestGetHistoryDateRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "no range", query: "", wantStatus: http.StatusOK},
+		{name: "valid range", query: "?from=01.02.2025&to=15.02.2025", wantStatus: http.StatusOK},
+		{name: "same day", query: "?from=15.02.2025&to=15.02.2025", wantStatus: http.StatusOK},
+		{name: "bad from format", query: "?from=2025-02-01", wantStatus: http.StatusBadRequest},
+		{name: "bad to date", query: "?to=31.02.2025", wantStatus: http.StatusBadRequest},
+		{name: "from after to", query: "?from=16.02.2025&to=15.02.2025", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ipUC := &fakeIPUseCase{}
+			w, resp := serve(newTestRouter(ipUC), http.MethodGet, "/api/v1/ips/192.0.2.10/history"+tt.query, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus == http.StatusBadRequest && (resp.Error != "invalid_date" || len(ipUC.historyIPs) != 0) {
+				t.Errorf("response = %+v, want invalid_date without calling the use case", resp)
+			}
+		})
+	}
+
+	ipUC := &fakeIPUseCase{}
+	serve(newTestRouter(ipUC), http.MethodGet, "/api/v1/ips/192.0.2.10/history?from=01.02.2025&to=15.02.2025", "")
+	wantFrom := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	wantTo := time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC)
+	if !ipUC.from.Equal(wantFrom) || !ipUC.to.Equal(wantTo) {
+		t.Errorf("range = %s - %s, want %s - %s", ipUC.from, ipUC.to, wantFrom, wantTo)
+	}
+}
@@ -0,0 +1,359 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"git.emercury.dev/emercury/senderscore/api/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type IPHandler struct {
+	ipUC usecase.IPUseCase
+}
+
+func NewIPHandler(ipUC usecase.IPUseCase) *IPHandler {
+	return &IPHandler{
+		ipUC: ipUC,
+	}
+}
+
+func (h *IPHandler) GetIP(c *gin.Context) {
+	ip := c.Param("ip")
+
+	dto, err := h.ipUC.GetIP(c.Request.Context(), ip)
+	if err != nil {
+		if err == domain.ErrIPNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "IP address not found",
+			})
+			return
+		}
+		logrus.WithError(err).WithField("ip", ip).Error("Failed to get IP")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve IP",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toIPResponse(dto))
+}
+
+func (h *IPHandler) ListIPs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	filter, ok := parseIPFilter(c)
+	if !ok {
+		return
+	}
+
+	pagination := usecase.PaginationDTO{
+		Page:     page,
+		PageSize: pageSize,
+	}.Normalize()
+
+	ips, total, err := h.ipUC.ListIPs(c.Request.Context(), filter, pagination)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list IPs")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve IPs",
+		})
+		return
+	}
+
+	responses := make([]IPResponse, len(ips))
+	for i, ip := range ips {
+		responses[i] = toIPResponse(ip)
+	}
+	totalPages := int(math.Ceil(float64(total) / float64(pagination.PageSize)))
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Data:       responses,
+		Page:       pagination.Page,
+		PageSize:   pagination.PageSize,
+		TotalItems: total,
+		TotalPages: totalPages,
+	})
+}
+
+func (h *IPHandler) RemoveFromGroup(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("group_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_group_id",
+			Message: "Invalid group_id format",
+		})
+		return
+	}
+	ip := c.Param("ip")
+
+	if err := h.ipUC.RemoveIPFromGroup(c.Request.Context(), groupID, ip); err != nil {
+		if !writeGroupIPError(c, err) {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"group_id": groupID,
+				"ip":       ip,
+			}).Error("Failed to remove IP from group")
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to remove IP from group",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "IP removed from group successfully",
+	})
+}
+
+func (h *IPHandler) MoveIP(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("group_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_group_id",
+			Message: "Invalid group_id format",
+		})
+		return
+	}
+	ip := c.Param("ip")
+
+	var req MoveIPRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.ipUC.MoveIP(c.Request.Context(), groupID, req.ToGroupID, ip); err != nil {
+		if !writeGroupIPError(c, err) {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"from_group_id": groupID,
+				"to_group_id":   req.ToGroupID,
+				"ip":            ip,
+			}).Error("Failed to move IP")
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to move IP",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "IP moved successfully",
+	})
+}
+
+// writeGroupIPError writes a 404 response for the not-found errors of group
+// IP operations and reports whether it did.
+func writeGroupIPError(c *gin.Context, err error) bool {
+	var message string
+	switch err {
+	case domain.ErrGroupNotFound:
+		message = "Group not found"
+	case domain.ErrIPNotFound:
+		message = "IP address not found"
+	case domain.ErrIPNotInGroup:
+		message = "IP address is not in group"
+	default:
+		return false
+	}
+
+	c.JSON(http.StatusNotFound, ErrorResponse{
+		Error:   "not_found",
+		Message: message,
+	})
+	return true
+}
+
+// parseIPFilter reads the IP list filters from the query string. It writes a
+// 400 response and returns false when one of them is malformed.
+func parseIPFilter(c *gin.Context) (usecase.IPFilterDTO, bool) {
+	filter := usecase.IPFilterDTO{
+		Search: c.Query("search"),
+		Status: c.Query("status"),
+	}
+
+	invalid := func(name string) (usecase.IPFilterDTO, bool) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_filter",
+			Message: "Invalid '" + name + "' filter",
+		})
+		return usecase.IPFilterDTO{}, false
+	}
+
+	if value := c.Query("group_id"); value != "" {
+		groupID, err := strconv.Atoi(value)
+		if err != nil {
+			return invalid("group_id")
+		}
+		filter.GroupID = groupID
+	}
+	if value := c.Query("min_score"); value != "" {
+		score, err := strconv.Atoi(value)
+		if err != nil {
+			return invalid("min_score")
+		}
+		filter.MinScore = &score
+	}
+	if value := c.Query("max_score"); value != "" {
+		score, err := strconv.Atoi(value)
+		if err != nil {
+			return invalid("max_score")
+		}
+		filter.MaxScore = &score
+	}
+	if value := c.Query("blocklisted"); value != "" {
+		blocklisted, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalid("blocklisted")
+		}
+		filter.Blocklisted = &blocklisted
+	}
+	if value := c.Query("min_complaint_rate"); value != "" {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return invalid("min_complaint_rate")
+		}
+		filter.MinComplaintRate = &rate
+	}
+	if value := c.Query("has_spam_traps"); value != "" {
+		hasSpamTraps, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalid("has_spam_traps")
+		}
+		filter.HasSpamTraps = &hasSpamTraps
+	}
+
+	return filter, true
+}
+
+func (h *IPHandler) ListScoreStats(c *gin.Context) {
+	ip := c.Param("ip")
+
+	stats, err := h.ipUC.ListScoreStats(c.Request.Context(), ip)
+	if err != nil {
+		if err == domain.ErrIPNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "IP address not found",
+			})
+			return
+		}
+		logrus.WithError(err).WithField("ip", ip).Error("Failed to list score stats")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve score stats",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toScoreStatResponses(stats))
+}
+
+func (h *IPHandler) GetHistory(c *gin.Context) {
+	ip := c.Param("ip")
+
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	history, err := h.ipUC.GetHistory(c.Request.Context(), ip, from, to)
+	if err != nil {
+		if err == domain.ErrIPNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "IP address not found",
+			})
+			return
+		}
+		logrus.WithError(err).WithField("ip", ip).Error("Failed to get IP history")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve history",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toHistoryPointResponses(history))
+}
+
+func (h *IPHandler) ListEvents(c *gin.Context) {
+	ip := c.Param("ip")
+	field := c.Query("field")
+
+	switch field {
+	case "", domain.IPEventFieldScore, domain.IPEventFieldSpamTrap, domain.IPEventFieldBlocklists, domain.IPEventFieldComplaints:
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_filter",
+			Message: "Invalid 'field', expected one of: score, spam_trap, blocklists, complaints",
+		})
+		return
+	}
+
+	events, err := h.ipUC.ListEvents(c.Request.Context(), ip, field)
+	if err != nil {
+		if err == domain.ErrIPNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "IP address not found",
+			})
+			return
+		}
+		logrus.WithError(err).WithField("ip", ip).Error("Failed to list IP events")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve IP events",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toIPEventResponses(events))
+}
+
+// parseDateRange reads the optional from/to query parameters in DD.MM.YYYY
+// format. It writes a 400 response and returns false when they are invalid.
+func parseDateRange(c *gin.Context) (time.Time, time.Time, bool) {
+	var from, to time.Time
+
+	for _, param := range []struct {
+		name   string
+		target *time.Time
+	}{
+		{name: "from", target: &from},
+		{name: "to", target: &to},
+	} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+
+		date, err := time.Parse("02.01.2006", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_date",
+				Message: "Invalid '" + param.name + "' date, expected DD.MM.YYYY",
+			})
+			return time.Time{}, time.Time{}, false
+		}
+		*param.target = date
+	}
+
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_date",
+			Message: "'from' must not be after 'to'",
+		})
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to, true
+}
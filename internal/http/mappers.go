@@ -1,6 +1,7 @@
 package http
 
 import (
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
 	"git.emercury.dev/emercury/senderscore/api/internal/usecase"
 )
 
@@ -16,6 +17,7 @@ func toAddIPDTO(req AddIPRequest) usecase.AddIPDTO {
 		GroupID:   req.GroupID,
 		GroupName: req.GroupName,
 		IP:        req.IP,
+		CIDR:      req.CIDR,
 	}
 }
 
@@ -32,8 +34,8 @@ func toHistoryEntryDTOs(entries []HistoryEntry) []usecase.HistoryEntryDTO {
 	for i, entry := range entries {
 		dtos[i] = usecase.HistoryEntryDTO{
 			Date:     entry.Date,
-			Score:    entry.Score,
-			Volume:   entry.Volume,
+			Score:    *entry.Score,
+			Volume:   *entry.Volume,
 			SpamTrap: entry.SpamTrap,
 		}
 	}
@@ -41,14 +43,19 @@ func toHistoryEntryDTOs(entries []HistoryEntry) []usecase.HistoryEntryDTO {
 }
 
 func toSubmitScoreDTO(req SubmitScoreRequest) usecase.SubmitScoreDTO {
-	return usecase.SubmitScoreDTO{
+	dto := usecase.SubmitScoreDTO{
 		IP:         req.IP,
-		Score:      req.Score,
+		Score:      *req.Score,
 		SpamTrap:   req.SpamTrap,
 		Blocklists: req.Blocklists,
 		Complaints: req.Complaints,
 		History:    toHistoryEntryDTOs(req.History),
 	}
+	// Клиенты API присылают исходный текст отчета, числа извлекаются здесь,
+	// как это делает парсер для отчетов senderscore.org
+	dto.BlocklistCount = domain.BlocklistCountOf(req.Blocklists)
+	dto.ComplaintRate, _ = domain.ParseComplaintRate(req.Complaints)
+	return dto
 }
 
 func toGroupResponse(dto *usecase.GroupDTO) GroupResponse {
@@ -61,7 +68,11 @@ func toGroupResponse(dto *usecase.GroupDTO) GroupResponse {
 			SpamTrap:   ip.SpamTrap,
 			Blocklists: ip.Blocklists,
 			Complaints: ip.Complaints,
+			Status:     ip.Status,
 			UpdatedAt:  ip.UpdatedAt,
+
+			BlocklistCount: ip.BlocklistCount,
+			ComplaintRate:  ip.ComplaintRate,
 		}
 	}
 
@@ -72,6 +83,14 @@ func toGroupResponse(dto *usecase.GroupDTO) GroupResponse {
 		SpamTrapCount: dto.SpamTrapCount,
 		IpsCount:      dto.IPsCount,
 		IPs:           ips,
+
+		AvgScore:           dto.AvgScore,
+		MinScore:           dto.MinScore,
+		WeightedScore:      dto.WeightedScore,
+		LowScoreCount:      dto.LowScoreCount,
+		CriticalScoreCount: dto.CriticalScoreCount,
+		BlocklistedCount:   dto.BlocklistedCount,
+		AvgComplaintRate:   dto.AvgComplaintRate,
 	}
 }
 
@@ -91,10 +110,80 @@ func toIPResponse(dto *usecase.IPDTO) IPResponse {
 		SpamTrap:   dto.SpamTrap,
 		Blocklists: dto.Blocklists,
 		Complaints: dto.Complaints,
+		Status:     dto.Status,
 		UpdatedAt:  dto.UpdatedAt,
+		GroupIDs:   dto.GroupIDs,
+
+		BlocklistCount: dto.BlocklistCount,
+		ComplaintRate:  dto.ComplaintRate,
 	}
 }
 
+func toHistoryPointResponses(dtos []usecase.HistoryEntryDTO) []HistoryPointResponse {
+	responses := make([]HistoryPointResponse, len(dtos))
+	for i, dto := range dtos {
+		responses[i] = HistoryPointResponse{
+			Date:     dto.Date,
+			Score:    dto.Score,
+			Volume:   dto.Volume,
+			SpamTrap: dto.SpamTrap,
+		}
+	}
+	return responses
+}
+
+func toGroupHistoryPointResponses(dtos []usecase.GroupHistoryPointDTO) []GroupHistoryPointResponse {
+	responses := make([]GroupHistoryPointResponse, len(dtos))
+	for i, dto := range dtos {
+		responses[i] = GroupHistoryPointResponse{
+			Date:     dto.Date,
+			AvgScore: dto.AvgScore,
+			MinScore: dto.MinScore,
+			Volume:   dto.Volume,
+			SpamTrap: dto.SpamTrap,
+			IPsCount: dto.IPsCount,
+		}
+	}
+	return responses
+}
+
+func toChannelResponse(dto *usecase.NotificationChannelDTO) ChannelResponse {
+	return ChannelResponse{
+		ID:      dto.ID,
+		GroupID: dto.GroupID,
+		Type:    dto.Type,
+		Target:  dto.Target,
+	}
+}
+
+func toIPEventResponses(dtos []*usecase.IPEventDTO) []IPEventResponse {
+	responses := make([]IPEventResponse, len(dtos))
+	for i, dto := range dtos {
+		responses[i] = IPEventResponse{
+			ID:        dto.ID,
+			Field:     dto.Field,
+			OldValue:  dto.OldValue,
+			NewValue:  dto.NewValue,
+			CreatedAt: dto.CreatedAt,
+		}
+	}
+	return responses
+}
+
+func toScoreStatResponses(dtos []*usecase.ScoreStatDTO) []ScoreStatResponse {
+	responses := make([]ScoreStatResponse, len(dtos))
+	for i, dto := range dtos {
+		responses[i] = ScoreStatResponse{
+			ID:     dto.ID,
+			Score:  dto.Score,
+			Result: dto.Result,
+			Status: domain.ScoreResultName(dto.Result),
+			Date:   dto.Date,
+		}
+	}
+	return responses
+}
+
 func toSubmitScoreResponse(dto *usecase.SubmitScoreResultDTO) SubmitScoreResponse {
 	return SubmitScoreResponse{
 		Success:        dto.Success,
@@ -107,10 +196,24 @@ func toSubmitScoreResponse(dto *usecase.SubmitScoreResultDTO) SubmitScoreRespons
 }
 
 func toAddIPsResponse(dto *usecase.BatchIPResultDTO) AddIPsResponse {
+	items := make([]AddIPItemResponse, len(dto.Items))
+	for i, item := range dto.Items {
+		items[i] = AddIPItemResponse{
+			Index:   item.Index,
+			IP:      item.IP,
+			GroupID: item.GroupID,
+			Status:  item.Status,
+			Reason:  item.Reason,
+		}
+	}
+
 	return AddIPsResponse{
 		GroupsCreated: dto.GroupsCreated,
 		IPsCreated:    dto.IPsCreated,
 		IPsSkipped:    dto.IPsSkipped,
+		IPsInvalid:    dto.IPsInvalid,
+		IPsFailed:     dto.IPsFailed,
 		Message:       dto.Message,
+		Items:         items,
 	}
 }
@@ -9,22 +9,25 @@ type AddIPRequest struct {
 	GroupID   int    `json:"group_id" binding:"required"`
 	GroupName string `json:"group_name"`
 	IP        string `json:"ip"`
+	CIDR      string `json:"cidr"`
 }
 
 type AddIPsRequest struct {
 	IPs []AddIPRequest `json:"ips" binding:"required,min=1,dive"`
 }
 
+// Score и Volume - указатели, чтобы required отличал отсутствующее поле
+// от допустимого нулевого значения.
 type HistoryEntry struct {
 	Date     string `json:"date" binding:"required"`
-	Score    int    `json:"score" binding:"required"`
-	Volume   int    `json:"volume" binding:"required"`
+	Score    *int   `json:"score" binding:"required"`
+	Volume   *int   `json:"volume" binding:"required"`
 	SpamTrap int    `json:"spam_trap"`
 }
 
 type SubmitScoreRequest struct {
 	IP         string         `json:"ip" binding:"required"`
-	Score      int            `json:"score" binding:"required"`
+	Score      *int           `json:"score" binding:"required"`
 	SpamTrap   int            `json:"spam_trap"`
 	Blocklists string         `json:"blocklists"`
 	Complaints string         `json:"complaints"`
@@ -32,12 +35,21 @@ type SubmitScoreRequest struct {
 }
 
 type GroupResponse struct {
-	ID            uint         `json:"id"`
-	GroupID       int          `json:"group_id"`
-	GroupName     string       `json:"group_name"`
-	SpamTrapCount int          `json:"spam_trap_count"`
-	IpsCount      int          `json:"ips_count"`
-	IPs           []IPResponse `json:"ips,omitempty"`
+	ID            uint   `json:"id"`
+	GroupID       int    `json:"group_id"`
+	GroupName     string `json:"group_name"`
+	SpamTrapCount int    `json:"spam_trap_count"`
+	IpsCount      int    `json:"ips_count"`
+
+	AvgScore           float64 `json:"avg_score"`
+	MinScore           int     `json:"min_score"`
+	WeightedScore      float64 `json:"weighted_score"`
+	LowScoreCount      int     `json:"low_score_count"`
+	CriticalScoreCount int     `json:"critical_score_count"`
+	BlocklistedCount   int     `json:"blocklisted_count"`
+	AvgComplaintRate   float64 `json:"avg_complaint_rate"`
+
+	IPs []IPResponse `json:"ips,omitempty"`
 }
 
 type IPResponse struct {
@@ -47,7 +59,60 @@ type IPResponse struct {
 	SpamTrap   int    `json:"spam_trap"`
 	Blocklists string `json:"blocklists,omitempty"`
 	Complaints string `json:"complaints,omitempty"`
+	Status     string `json:"status"`
 	UpdatedAt  int64  `json:"updated_at"`
+	GroupIDs   []int  `json:"group_ids,omitempty"`
+
+	BlocklistCount int     `json:"blocklist_count"`
+	ComplaintRate  float64 `json:"complaint_rate"`
+}
+
+type CreateChannelRequest struct {
+	Type   string `json:"type" binding:"required,oneof=chat email"`
+	Target string `json:"target" binding:"required"`
+}
+
+type ChannelResponse struct {
+	ID      uint   `json:"id"`
+	GroupID int    `json:"group_id"`
+	Type    string `json:"type"`
+	Target  string `json:"target"`
+}
+
+type IPEventResponse struct {
+	ID        uint   `json:"id"`
+	Field     string `json:"field"`
+	OldValue  string `json:"old_value"`
+	NewValue  string `json:"new_value"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type MoveIPRequest struct {
+	ToGroupID int `json:"to_group_id" binding:"required"`
+}
+
+type HistoryPointResponse struct {
+	Date     string `json:"date"`
+	Score    int    `json:"score"`
+	Volume   int    `json:"volume"`
+	SpamTrap int    `json:"spam_trap"`
+}
+
+type GroupHistoryPointResponse struct {
+	Date     string  `json:"date"`
+	AvgScore float64 `json:"avg_score"`
+	MinScore int     `json:"min_score"`
+	Volume   int     `json:"volume"`
+	SpamTrap int     `json:"spam_trap"`
+	IPsCount int     `json:"ips_count"`
+}
+
+type ScoreStatResponse struct {
+	ID     uint   `json:"id"`
+	Score  int    `json:"score"`
+	Result int    `json:"result"`
+	Status string `json:"status"`
+	Date   string `json:"date"`
 }
 
 type SubmitScoreResponse struct {
@@ -60,10 +125,21 @@ type SubmitScoreResponse struct {
 }
 
 type AddIPsResponse struct {
-	GroupsCreated int    `json:"groups_created"`
-	IPsCreated    int    `json:"ips_created"`
-	IPsSkipped    int    `json:"ips_skipped"`
-	Message       string `json:"message"`
+	GroupsCreated int                 `json:"groups_created"`
+	IPsCreated    int                 `json:"ips_created"`
+	IPsSkipped    int                 `json:"ips_skipped"`
+	IPsInvalid    int                 `json:"ips_invalid"`
+	IPsFailed     int                 `json:"ips_failed"`
+	Message       string              `json:"message"`
+	Items         []AddIPItemResponse `json:"items"`
+}
+
+type AddIPItemResponse struct {
+	Index   int    `json:"index"`
+	IP      string `json:"ip"`
+	GroupID int    `json:"group_id"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
 }
 
 type ErrorResponse struct {
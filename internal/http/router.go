@@ -7,6 +7,7 @@ import (
 func RegisterRoutes(
 	router *gin.Engine,
 	groupHandler *GroupHandler,
+	ipHandler *IPHandler,
 	authMiddleware gin.HandlerFunc,
 ) {
 	router.GET("/health", func(c *gin.Context) {
@@ -25,6 +26,7 @@ func RegisterRoutes(
 			groups.GET("", groupHandler.ListGroups)
 			groups.GET("/:id", groupHandler.GetGroup)
 			groups.GET("/by-group-id/:group_id", groupHandler.GetGroupByGroupID)
+			groups.GET("/by-group-id/:group_id/history", groupHandler.GetGroupHistory)
 
 			// Protected routes
 			groups.POST("", authMiddleware, groupHandler.CreateGroup)
@@ -32,13 +34,23 @@ func RegisterRoutes(
 			groups.DELETE("/by-group-id/:group_id", authMiddleware, groupHandler.DeleteGroup)
 			groups.POST("/ips", authMiddleware, groupHandler.AddIP)
 			groups.POST("/ips/batch", authMiddleware, groupHandler.AddIPs)
+			groups.DELETE("/by-group-id/:group_id/ips/:ip", authMiddleware, ipHandler.RemoveFromGroup)
+			groups.POST("/by-group-id/:group_id/ips/:ip/move", authMiddleware, ipHandler.MoveIP)
+			groups.GET("/by-group-id/:group_id/channels", authMiddleware, groupHandler.ListChannels)
+			groups.POST("/by-group-id/:group_id/channels", authMiddleware, groupHandler.AddChannel)
+			groups.DELETE("/by-group-id/:group_id/channels/:id", authMiddleware, groupHandler.DeleteChannel)
 		}
 
 		// IPs routes
 		ips := v1.Group("/ips")
 		{
 			// Public routes
+			ips.GET("", ipHandler.ListIPs)
 			ips.GET("/oldest", groupHandler.GetOldestIP)
+			ips.GET("/:ip", ipHandler.GetIP)
+			ips.GET("/:ip/stats", ipHandler.ListScoreStats)
+			ips.GET("/:ip/history", ipHandler.GetHistory)
+			ips.GET("/:ip/events", ipHandler.ListEvents)
 		}
 
 		// Scores routes
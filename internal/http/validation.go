@@ -0,0 +1,193 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const dateLayout = "02.01.2006"
+
+func init() {
+	// Ошибки биндинга должны ссылаться на поля так, как они названы в JSON
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// fieldErrors maps a JSON field path such as "history[2].date" to the
+// reason the value was rejected.
+type fieldErrors map[string]interface{}
+
+func (e fieldErrors) add(field, format string, args ...interface{}) {
+	if _, ok := e[field]; ok {
+		return
+	}
+	e[field] = fmt.Sprintf(format, args...)
+}
+
+// validatable is implemented by requests with checks that binding tags
+// cannot express.
+type validatable interface {
+	validate(errs fieldErrors)
+}
+
+// bindJSON binds the request body into req and validates it. It writes a
+// 400 response with per-field details and returns false when the body is
+// rejected.
+func bindJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		writeValidationError(c, err)
+		return false
+	}
+
+	if v, ok := req.(validatable); ok {
+		errs := fieldErrors{}
+		v.validate(errs)
+		if len(errs) > 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Request validation failed",
+				Details: errs,
+			})
+			return false
+		}
+	}
+
+	return true
+}
+
+func writeValidationError(c *gin.Context, err error) {
+	errs := fieldErrors{}
+
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fe := range validationErrs {
+			errs.add(fieldPath(fe.Namespace()), "%s", validationMessage(fe))
+		}
+	case errors.As(err, &typeErr):
+		errs.add(typeErr.Field, "must be %s", typeErr.Type.Kind())
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Malformed JSON body",
+		})
+		return
+	}
+
+	if len(errs) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, ErrorResponse{
+		Error:   "invalid_request",
+		Message: "Request validation failed",
+		Details: errs,
+	})
+}
+
+// fieldPath drops the request struct name from a validator namespace,
+// e.g. "SubmitScoreRequest.history[0].date" becomes "history[0].date".
+func fieldPath(namespace string) string {
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at least %s item(s)", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fe.Param(), " ", ", "))
+	default:
+		return fmt.Sprintf("failed %q validation", fe.Tag())
+	}
+}
+
+func validateIP(errs fieldErrors, field, address string) {
+	if address == "" {
+		return
+	}
+	if _, err := domain.CanonicalIP(address); err != nil {
+		errs.add(field, "must be a valid IPv4 or IPv6 address")
+	}
+}
+
+func validateScore(errs fieldErrors, field string, score int) {
+	switch {
+	case score < 0:
+		errs.add(field, "must not be negative")
+	case score > 100:
+		errs.add(field, "must be at most 100")
+	}
+}
+
+// Элементы пакетного добавления не проверяются здесь: AddIPs возвращает
+// статус invalid для каждого неверного адреса, не отклоняя весь пакет.
+func (r AddIPRequest) validate(errs fieldErrors) {
+	validateIP(errs, "ip", r.IP)
+	if r.IP != "" && r.CIDR != "" {
+		errs.add("cidr", "must not be set together with ip")
+	}
+}
+
+func (r SubmitScoreRequest) validate(errs fieldErrors) {
+	validateIP(errs, "ip", r.IP)
+	validateScore(errs, "score", *r.Score)
+	if r.SpamTrap < 0 {
+		errs.add("spam_trap", "must not be negative")
+	}
+
+	seen := make(map[string]int, len(r.History))
+	for i, entry := range r.History {
+		prefix := fmt.Sprintf("history[%d].", i)
+
+		if _, err := time.Parse(dateLayout, entry.Date); err != nil {
+			errs.add(prefix+"date", "must be a date in DD.MM.YYYY format")
+		} else if first, ok := seen[entry.Date]; ok {
+			errs.add(prefix+"date", "duplicates history[%d].date", first)
+		} else {
+			seen[entry.Date] = i
+		}
+
+		validateScore(errs, prefix+"score", *entry.Score)
+		if *entry.Volume < 0 {
+			errs.add(prefix+"volume", "must not be negative")
+		}
+		if entry.SpamTrap < 0 {
+			errs.add(prefix+"spam_trap", "must not be negative")
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package infrastructure
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+)
+
+const (
+	ArchiveNone = "none"
+	ArchiveDB   = "db"
+	ArchiveDir  = "dir"
+
+	archiveFileSuffix = ".html.gz"
+)
+
+// dirArchive stores every page as <dir>/<ip>/<unix millis>.<source>.html.gz.
+type dirArchive struct {
+	dir string
+}
+
+func NewDirArchive(dir string) domain.ReportArchive {
+	return &dirArchive{dir: dir}
+}
+
+func (a *dirArchive) Store(ctx context.Context, report *domain.RawReport) error {
+	ipDir := filepath.Join(a.dir, report.IP)
+	if err := os.MkdirAll(ipDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%d.%s%s", report.FetchedAt.UnixMilli(), report.Source, archiveFileSuffix)
+	f, err := os.Create(filepath.Join(ipDir, name))
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer f.Close()
+
+	w := gzip.NewWriter(f)
+	if _, err := w.Write(report.Body); err != nil {
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	return f.Close()
+}
+
+func (a *dirArchive) Each(ctx context.Context, filter domain.RawReportFilter, fn func(report *domain.RawReport) error) error {
+	var ips []string
+	if filter.IP != "" {
+		ips = []string{filter.IP}
+	} else {
+		entries, err := os.ReadDir(a.dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to read archive directory: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				ips = append(ips, entry.Name())
+			}
+		}
+	}
+
+	var reports []*domain.RawReport
+	for _, ip := range ips {
+		entries, err := os.ReadDir(filepath.Join(a.dir, ip))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to read archive directory for %s: %w", ip, err)
+		}
+
+		for _, entry := range entries {
+			report, ok := parseArchiveFileName(ip, entry.Name())
+			if !ok || report.FetchedAt.Before(filter.Since) {
+				continue
+			}
+			reports = append(reports, report)
+		}
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].FetchedAt.Before(reports[j].FetchedAt)
+	})
+
+	for _, report := range reports {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		body, err := a.read(report)
+		if err != nil {
+			return err
+		}
+		report.Body = body
+
+		if err := fn(report); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *dirArchive) read(report *domain.RawReport) ([]byte, error) {
+	name := fmt.Sprintf("%d.%s%s", report.FetchedAt.UnixMilli(), report.Source, archiveFileSuffix)
+	f, err := os.Open(filepath.Join(a.dir, report.IP, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive file: %w", err)
+	}
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive file %s: %w", name, err)
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func parseArchiveFileName(ip, name string) (*domain.RawReport, bool) {
+	base, ok := strings.CutSuffix(name, archiveFileSuffix)
+	if !ok {
+		return nil, false
+	}
+
+	millis, source, ok := strings.Cut(base, ".")
+	if !ok {
+		return nil, false
+	}
+
+	ms, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+
+	return &domain.RawReport{
+		IP:        ip,
+		Source:    source,
+		FetchedAt: time.UnixMilli(ms),
+	}, true
+}
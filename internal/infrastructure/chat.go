@@ -0,0 +1,95 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+)
+
+// chatSender отправляет алерты во входящие вебхуки Slack и Mattermost.
+// Оба принимают одинаковый формат с text и attachments.
+type chatSender struct {
+	client *http.Client
+}
+
+func NewChatSender(client *http.Client) domain.ChannelSender {
+	return &chatSender{client: client}
+}
+
+type chatMessage struct {
+	Text        string           `json:"text"`
+	Attachments []chatAttachment `json:"attachments,omitempty"`
+}
+
+type chatAttachment struct {
+	Color  string      `json:"color"`
+	Fields []chatField `json:"fields"`
+}
+
+type chatField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func (s *chatSender) Send(ctx context.Context, target string, alert *domain.Alert) error {
+	body, err := json.Marshal(chatMessage{
+		Text: fmt.Sprintf(":warning: *%s*", alertSubject(alert)),
+		Attachments: []chatAttachment{{
+			Color: "#FF1744",
+			Fields: []chatField{
+				{Title: "IP", Value: alert.IP, Short: true},
+				{Title: "Rule", Value: alert.Rule, Short: true},
+				{Title: "Score", Value: strconv.Itoa(alert.Score), Short: true},
+				{Title: "Previous Score", Value: strconv.Itoa(alert.PreviousScore), Short: true},
+				{Title: "Spam Traps", Value: strconv.Itoa(alert.SpamTrap), Short: true},
+				{Title: "Blocklists", Value: alert.Blocklists, Short: true},
+				{Title: "Groups", Value: joinGroupIDs(alert.GroupIDs), Short: false},
+			},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal chat message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func alertSubject(alert *domain.Alert) string {
+	return fmt.Sprintf("SenderScore alert for %s: %s", alert.IP, alert.Message)
+}
+
+func joinGroupIDs(groupIDs []int) string {
+	if len(groupIDs) == 0 {
+		return "-"
+	}
+	ids := make([]string, len(groupIDs))
+	for i, id := range groupIDs {
+		ids[i] = strconv.Itoa(id)
+	}
+	return strings.Join(ids, ", ")
+}
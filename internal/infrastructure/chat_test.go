@@ -0,0 +1,86 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+)
+
+func testAlert() *domain.Alert {
+	return &domain.Alert{
+		Rule:               domain.AlertRuleScoreBelow,
+		IP:                 "192.0.2.10",
+		GroupIDs:           []int{42, 7},
+		Score:              61,
+		PreviousScore:      83,
+		SpamTrap:           2,
+		Blocklists:         "1",
+		PreviousBlocklists: "0",
+		Message:            "Sender score 61 is below 70",
+		TriggeredAt:        time.Unix(1739577600, 0),
+	}
+}
+
+func TestChatSenderSend(t *testing.T) {
+	var got chatMessage
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode chat message: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	sender := NewChatSender(server.Client())
+	if err := sender.Send(context.Background(), server.URL, testAlert()); err != nil {
+		t.Fatalf("Send() unexpected error: %v", err)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+	if !strings.Contains(got.Text, "192.0.2.10") || !strings.Contains(got.Text, "Sender score 61 is below 70") {
+		t.Errorf("Text = %q, want IP and alert message", got.Text)
+	}
+	if len(got.Attachments) != 1 {
+		t.Fatalf("got %d attachments, want 1", len(got.Attachments))
+	}
+
+	fields := make(map[string]string)
+	for _, f := range got.Attachments[0].Fields {
+		fields[f.Title] = f.Value
+	}
+	want := map[string]string{
+		"IP":             "192.0.2.10",
+		"Rule":           domain.AlertRuleScoreBelow,
+		"Score":          "61",
+		"Previous Score": "83",
+		"Spam Traps":     "2",
+		"Blocklists":     "1",
+		"Groups":         "42, 7",
+	}
+	for title, value := range want {
+		if fields[title] != value {
+			t.Errorf("field %q = %q, want %q", title, fields[title], value)
+		}
+	}
+}
+
+func TestChatSenderSendErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	sender := NewChatSender(server.Client())
+	if err := sender.Send(context.Background(), server.URL, testAlert()); err == nil {
+		t.Fatal("Send() error = nil, want error for status 404")
+	}
+}
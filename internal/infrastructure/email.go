@@ -0,0 +1,152 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+type emailSender struct {
+	cfg SMTPConfig
+}
+
+// NewEmailSender отправляет алерты письмом через SMTP. Авторизация
+// используется только если задан Username.
+func NewEmailSender(cfg SMTPConfig) domain.ChannelSender {
+	return &emailSender{cfg: cfg}
+}
+
+// smtpTimeout ограничивает отправку письма, если у ctx нет своего дедлайна
+const smtpTimeout = 30 * time.Second
+
+func (s *emailSender) Send(ctx context.Context, target string, alert *domain.Alert) error {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set SMTP deadline: %w", err)
+	}
+
+	// Отмена ctx прерывает зависший обмен с сервером
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := s.deliver(conn, target, s.buildMessage(target, alert)); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+		return fmt.Errorf("failed to send email to %s: %w", target, err)
+	}
+
+	return nil
+}
+
+// deliver повторяет smtp.SendMail на уже открытом соединении.
+func (s *emailSender) deliver(conn net.Conn, to string, msg []byte) error {
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return err
+		}
+	}
+
+	if s.cfg.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("server does not support AUTH")
+		}
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(s.cfg.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (s *emailSender) buildMessage(to string, alert *domain.Alert) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", encodeHeader(alertSubject(alert)))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+
+	fmt.Fprintf(&buf, "%s\r\n\r\n", alert.Message)
+	fmt.Fprintf(&buf, "IP: %s\r\n", alert.IP)
+	fmt.Fprintf(&buf, "Rule: %s\r\n", alert.Rule)
+	fmt.Fprintf(&buf, "Score: %d (previous %d)\r\n", alert.Score, alert.PreviousScore)
+	fmt.Fprintf(&buf, "Spam Traps: %d\r\n", alert.SpamTrap)
+	fmt.Fprintf(&buf, "Blocklists: %s\r\n", alert.Blocklists)
+	fmt.Fprintf(&buf, "Groups: %s\r\n", joinGroupIDs(alert.GroupIDs))
+	fmt.Fprintf(&buf, "Triggered At: %s\r\n", alert.TriggeredAt.Format("02.01.2006 15:04:05"))
+
+	return buf.Bytes()
+}
+
+// encodeHeader убирает из значения заголовка переводы строк и управляющие
+// символы (текст блоклистов приходит со страницы отчета) и кодирует не-ASCII.
+func encodeHeader(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, value)
+	return mime.QEncoding.Encode("utf-8", strings.Join(strings.Fields(value), " "))
+}
@@ -0,0 +1,173 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"mime"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpStub is a minimal in-process SMTP server that accepts one message.
+type smtpStub struct {
+	listener net.Listener
+	from     string
+	to       string
+	data     string
+	done     chan struct{}
+}
+
+func newSMTPStub(t *testing.T, silent bool) *smtpStub {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	stub := &smtpStub{listener: listener, done: make(chan struct{})}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		defer close(stub.done)
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		if silent {
+			// Сервер принимает соединение, но не отвечает
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			conn.Read(make([]byte, 1))
+			return
+		}
+		stub.serve(textproto.NewConn(conn))
+	}()
+
+	return stub
+}
+
+func (s *smtpStub) serve(conn *textproto.Conn) {
+	conn.PrintfLine("220 localhost ESMTP stub")
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			conn.PrintfLine("250 localhost")
+		case "MAIL":
+			s.from = strings.TrimPrefix(line, "MAIL FROM:")
+			conn.PrintfLine("250 OK")
+		case "RCPT":
+			s.to = strings.TrimPrefix(line, "RCPT TO:")
+			conn.PrintfLine("250 OK")
+		case "DATA":
+			conn.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			lines, err := conn.ReadDotLines()
+			if err != nil {
+				return
+			}
+			s.data = strings.Join(lines, "\n")
+			conn.PrintfLine("250 OK")
+		case "QUIT":
+			conn.PrintfLine("221 Bye")
+			return
+		default:
+			conn.PrintfLine("502 Not implemented")
+		}
+	}
+}
+
+func (s *smtpStub) config() SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	return SMTPConfig{Host: host, Port: portNum, From: "senderscore@example.com"}
+}
+
+func TestEmailSenderSend(t *testing.T) {
+	stub := newSMTPStub(t, false)
+
+	sender := NewEmailSender(stub.config())
+	if err := sender.Send(context.Background(), "owner@example.com", testAlert()); err != nil {
+		t.Fatalf("Send() unexpected error: %v", err)
+	}
+	<-stub.done
+
+	if stub.from != "<senderscore@example.com>" {
+		t.Errorf("MAIL FROM = %q", stub.from)
+	}
+	if stub.to != "<owner@example.com>" {
+		t.Errorf("RCPT TO = %q", stub.to)
+	}
+
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(stub.data + "\n")))
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("failed to parse message header: %v", err)
+	}
+	if got := header.Get("Subject"); !strings.Contains(got, "192.0.2.10") {
+		t.Errorf("Subject = %q, want alert IP", got)
+	}
+	if got := header.Get("To"); got != "owner@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	for _, want := range []string{"Sender score 61 is below 70", "Score: 61 (previous 83)", "Groups: 42, 7"} {
+		if !strings.Contains(stub.data, want) {
+			t.Errorf("message body does not contain %q:\n%s", want, stub.data)
+		}
+	}
+}
+
+func TestEmailSenderSendSanitizesSubject(t *testing.T) {
+	stub := newSMTPStub(t, false)
+
+	alert := testAlert()
+	alert.Message = "Blocklists changed to 1\r\nBcc: attacker@example.com\n\tЧС"
+	if err := NewEmailSender(stub.config()).Send(context.Background(), "owner@example.com", alert); err != nil {
+		t.Fatalf("Send() unexpected error: %v", err)
+	}
+	<-stub.done
+
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(stub.data + "\n")))
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("failed to parse message header: %v", err)
+	}
+	if got := header.Get("Bcc"); got != "" {
+		t.Errorf("Bcc = %q, want no injected header", got)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("failed to decode Subject %q: %v", header.Get("Subject"), err)
+	}
+	want := "SenderScore alert for 192.0.2.10: Blocklists changed to 1 Bcc: attacker@example.com ЧС"
+	if subject != want {
+		t.Errorf("Subject = %q, want %q", subject, want)
+	}
+}
+
+func TestEmailSenderSendHonoursContext(t *testing.T) {
+	stub := newSMTPStub(t, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := NewEmailSender(stub.config()).Send(ctx, "owner@example.com", testAlert())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Send() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Send() returned after %s, want it bounded by the context", elapsed)
+	}
+}
@@ -2,29 +2,102 @@ package infrastructure
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure/senderscore"
 	"github.com/PuerkitoBio/goquery"
 )
 
+var ErrInvalidReport = errors.New("invalid sender score report")
+
+const (
+	ReportStatusValid            = "valid"
+	ReportStatusNoData           = "no_data"
+	ReportStatusBlocked          = "blocked"
+	ReportStatusUnexpectedLayout = "unexpected_layout"
+)
+
+var noDataMarkers = []string{
+	"no score is available",
+	"no data available",
+	"not enough data",
+}
+
+// challengeMarkers identify a bot-check interstitial served instead of a report.
+var challengeMarkers = []string{
+	"cf-turnstile",
+	"challenge-platform",
+	"g-recaptcha",
+	"h-captcha",
+	"verify you are human",
+	"<title>just a moment",
+}
+
+const (
+	FieldSpamTraps   = "spam_traps"
+	FieldBlocklists  = "blocklists"
+	FieldComplaints  = "complaints"
+	FieldSenderScore = "sender_score"
+	FieldSSTrend     = "ss_trend"
+	FieldSSVolume    = "ss_volume"
+
+	ProblemMissing = "missing"
+	ProblemInvalid = "invalid"
+)
+
 type TrendPoint struct {
 	Timestamp string `json:"timestamp"`
 	Value     int    `json:"value"`
 }
 
+type FieldIssue struct {
+	Field   string `json:"field"`
+	Problem string `json:"problem"`
+	Detail  string `json:"detail,omitempty"`
+}
+
 type Result struct {
-	SpamTrap    int          `json:"spam_trap"`
-	Blocklists  string       `json:"blocklists"`
-	Complaints  string       `json:"complaints"`
-	SenderScore int          `json:"sender_score"`
-	SSTrend     []TrendPoint `json:"ss_trend"`
-	SSVolume    []TrendPoint `json:"ss_volume"`
+	Status         string       `json:"status"`
+	SpamTrap       int          `json:"spam_trap"`
+	Blocklists     string       `json:"blocklists"`
+	BlocklistCount int          `json:"blocklist_count"`
+	Complaints     string       `json:"complaints"`
+	ComplaintRate  float64      `json:"complaint_rate"`
+	SenderScore    int          `json:"sender_score"`
+	SSTrend        []TrendPoint `json:"ss_trend"`
+	SSVolume       []TrendPoint `json:"ss_volume"`
+	Issues         []FieldIssue `json:"issues,omitempty"`
+}
+
+// ValidationError is returned by Parse when a field required to persist the
+// report could not be extracted. It unwraps to ErrInvalidReport.
+type ValidationError struct {
+	Issues []FieldIssue
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		parts[i] = fmt.Sprintf("%s %s", issue.Field, issue.Problem)
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidReport, strings.Join(parts, ", "))
 }
 
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidReport
+}
+
+var (
+	reScore  = regexp.MustCompile(`ssData.senderscore = ([0-9]+);`)
+	reTrend  = regexp.MustCompile(`ssData.ss_trend = \[([^\]]+)\];`)
+	reVolume = regexp.MustCompile(`ssData.ss_volume_trend = \[([^\]]+)\];`)
+)
+
 type Parser struct {
 	source string
 }
@@ -35,15 +108,38 @@ func NewParser(source string) *Parser {
 	}
 }
 
-func (p *Parser) Parse() *Result {
+// Parse classifies the page and extracts the report from it. A bot-check
+// page without the report table yields senderscore.ErrBlocked and a "no
+// score available" page a result with ReportStatusNoData. Every field that
+// could not be extracted from a report is listed in Result.Issues. Missing
+// trend data and blocklists or complaints text that is not a number are
+// tolerated (the text is kept, the numeric field stays 0); any other issue
+// makes Parse return a *ValidationError together with the partially filled
+// result.
+func (p *Parser) Parse() (*Result, error) {
 	reader := strings.NewReader(p.source)
 
 	doc, err := goquery.NewDocumentFromReader(reader)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("failed to read report HTML: %w", err)
+	}
+
+	// Cloudflare и капча встраивают свои скрипты и в обычные страницы, поэтому
+	// страница с таблицей отчета проверкой на бота не считается
+	hasReport := doc.Find("#repTable").Length() > 0
+	if !hasReport && isChallengePage(p.source) {
+		result := &Result{Status: ReportStatusBlocked}
+		return result, fmt.Errorf("%w: bot check page served instead of report", senderscore.ErrBlocked)
+	}
+
+	result := Result{Status: ReportStatusValid}
+
+	if !hasReport && hasNoDataMarker(doc.Text()) {
+		result.Status = ReportStatusNoData
+		return &result, nil
 	}
 
-	result := Result{}
+	found := make(map[string]bool)
 
 	doc.Find("#repTable tr").Each(func(i int, s *goquery.Selection) {
 		cells := s.Find("td")
@@ -53,15 +149,42 @@ func (p *Parser) Parse() *Result {
 
 			switch key {
 			case "Spam Traps":
-				val, _ := strconv.Atoi(valStr)
+				found[FieldSpamTraps] = true
+				val, err := strconv.Atoi(valStr)
+				if err != nil {
+					result.addIssue(FieldSpamTraps, ProblemInvalid, valStr)
+					return
+				}
 				result.SpamTrap = val
 			case "Blocklists":
+				found[FieldBlocklists] = true
 				result.Blocklists = valStr
+				count, ok := domain.ParseBlocklistCount(valStr)
+				if !ok {
+					result.BlocklistCount = domain.BlocklistCountUnknown
+					result.addIssue(FieldBlocklists, ProblemInvalid, valStr)
+					return
+				}
+				result.BlocklistCount = count
 			case "Complaints":
+				found[FieldComplaints] = true
 				result.Complaints = valStr
+				rate, ok := domain.ParseComplaintRate(valStr)
+				if !ok {
+					result.addIssue(FieldComplaints, ProblemInvalid, valStr)
+					return
+				}
+				result.ComplaintRate = rate
 			}
 		}
 	})
+
+	for _, field := range []string{FieldSpamTraps, FieldBlocklists, FieldComplaints} {
+		if !found[field] {
+			result.addIssue(field, ProblemMissing, "")
+		}
+	}
+
 	scriptText := ""
 	doc.Find("script").Each(func(i int, s *goquery.Selection) {
 		if strings.Contains(s.Text(), "ssData.ss_trend") {
@@ -69,33 +192,88 @@ func (p *Parser) Parse() *Result {
 		}
 	})
 
-	reScore := regexp.MustCompile(`ssData.senderscore = ([0-9]+);`)
 	scoreMatches := reScore.FindStringSubmatch(scriptText)
 	if len(scoreMatches) > 1 {
-		result.SenderScore, _ = strconv.Atoi(scoreMatches[1])
+		score, err := strconv.Atoi(scoreMatches[1])
+		if err != nil || score > 100 {
+			result.addIssue(FieldSenderScore, ProblemInvalid, scoreMatches[1])
+		} else {
+			result.SenderScore = score
+		}
+	} else {
+		result.addIssue(FieldSenderScore, ProblemMissing, "")
 	}
 
-	reTrend := regexp.MustCompile(`ssData.ss_trend = \[([^\]]+)\];`)
 	trendMatches := reTrend.FindStringSubmatch(scriptText)
-
 	if len(trendMatches) > 1 {
 		jsonStr := "[" + trendMatches[1] + "]"
-		err := json.Unmarshal([]byte(jsonStr), &result.SSTrend)
-		if err != nil {
-			fmt.Println("Error parse JSON trends:", err)
+		if err := json.Unmarshal([]byte(jsonStr), &result.SSTrend); err != nil {
+			result.addIssue(FieldSSTrend, ProblemInvalid, err.Error())
 		}
+	} else {
+		result.addIssue(FieldSSTrend, ProblemMissing, "")
 	}
 
-	reVolume := regexp.MustCompile(`ssData.ss_volume_trend = \[([^\]]+)\];`)
 	volumeMatches := reVolume.FindStringSubmatch(scriptText)
-
 	if len(volumeMatches) > 1 {
 		jsonStr := "[" + volumeMatches[1] + "]"
-		err := json.Unmarshal([]byte(jsonStr), &result.SSVolume)
-		if err != nil {
-			fmt.Println("Error parse JSON volume:", err)
+		if err := json.Unmarshal([]byte(jsonStr), &result.SSVolume); err != nil {
+			result.addIssue(FieldSSVolume, ProblemInvalid, err.Error())
+		}
+	} else {
+		result.addIssue(FieldSSVolume, ProblemMissing, "")
+	}
+
+	if err := result.validate(); err != nil {
+		result.Status = ReportStatusUnexpectedLayout
+		return &result, err
+	}
+
+	return &result, nil
+}
+
+func isChallengePage(body string) bool {
+	body = strings.ToLower(body)
+	for _, marker := range challengeMarkers {
+		if strings.Contains(body, marker) {
+			return true
 		}
 	}
+	return false
+}
 
-	return &result
+func hasNoDataMarker(text string) bool {
+	text = strings.ToLower(text)
+	for _, marker := range noDataMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Result) addIssue(field, problem, detail string) {
+	r.Issues = append(r.Issues, FieldIssue{
+		Field:   field,
+		Problem: problem,
+		Detail:  detail,
+	})
+}
+
+func (r *Result) validate() error {
+	var fatal []FieldIssue
+	for _, issue := range r.Issues {
+		optional := issue.Problem == ProblemMissing &&
+			(issue.Field == FieldSSTrend || issue.Field == FieldSSVolume) ||
+			issue.Problem == ProblemInvalid &&
+				(issue.Field == FieldBlocklists || issue.Field == FieldComplaints)
+		if !optional {
+			fatal = append(fatal, issue)
+		}
+	}
+
+	if len(fatal) > 0 {
+		return &ValidationError{Issues: fatal}
+	}
+	return nil
 }
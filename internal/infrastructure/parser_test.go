@@ -0,0 +1,72 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure/senderscore"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite testdata/*.golden.json with the current parser output")
+
+func TestParserParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		wantErr error
+	}{
+		{name: "normal IP", fixture: "report_normal"},
+		{name: "IP with blocklists", fixture: "report_blocklisted"},
+		{name: "non-numeric blocklists", fixture: "report_blocklisted_text"},
+		{name: "page without trend data", fixture: "report_no_trend"},
+		{name: "unknown IP", fixture: "report_unknown_ip"},
+		{name: "report with challenge scripts", fixture: "report_with_challenge_script"},
+		{name: "captcha page", fixture: "report_captcha", wantErr: senderscore.ErrBlocked},
+		{name: "unexpected layout", fixture: "report_unexpected_layout", wantErr: ErrInvalidReport},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := os.ReadFile(filepath.Join("testdata", tt.fixture+".html"))
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			result, err := NewParser(string(source)).Parse()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			got, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				t.Fatalf("failed to marshal result: %v", err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", tt.fixture+".golden.json")
+			if *updateGolden {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatalf("failed to write golden file: %v", err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("Parse() result mismatch for %s\n got: %s\nwant: %s", tt.fixture, got, want)
+			}
+		})
+	}
+}
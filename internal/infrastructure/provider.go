@@ -0,0 +1,149 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"git.emercury.dev/emercury/senderscore/api/internal/infrastructure/senderscore"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ProviderSenderScore = "senderscore"
+	ProviderFile        = "file"
+)
+
+type senderScoreProvider struct {
+	client  *senderscore.SenderClient
+	archive domain.ReportArchive
+}
+
+// NewSenderScoreProvider scrapes senderscore.org. When archive is not nil
+// every fetched page except bot-check pages is stored in it.
+func NewSenderScoreProvider(client *senderscore.SenderClient, archive domain.ReportArchive) domain.ScoreProvider {
+	return &senderScoreProvider{
+		client:  client,
+		archive: archive,
+	}
+}
+
+func (p *senderScoreProvider) Name() string {
+	return ProviderSenderScore
+}
+
+func (p *senderScoreProvider) Fetch(ctx context.Context, ip string) (*domain.ScoreReport, error) {
+	page, err := p.client.GetReport(ctx, ip)
+	if err != nil {
+		return nil, err
+	}
+	fetchedAt := time.Now().Truncate(time.Millisecond)
+
+	report, err := parseReport(p.Name(), ip, page)
+	if report != nil {
+		report.FetchedAt = fetchedAt
+	}
+
+	if p.archive != nil && !errors.Is(err, senderscore.ErrBlocked) {
+		raw := &domain.RawReport{
+			IP:        ip,
+			Source:    p.Name(),
+			FetchedAt: fetchedAt,
+			Body:      []byte(page),
+		}
+		if err := p.archive.Store(ctx, raw); err != nil {
+			logrus.WithError(err).WithField("ip", ip).Warn("Failed to archive raw report")
+		}
+	}
+
+	return report, err
+}
+
+// fileProvider replays report pages saved as <dir>/<ip>.html. It is used
+// for fixtures and for re-running the parser without hitting the network.
+type fileProvider struct {
+	dir string
+}
+
+func NewFileProvider(dir string) domain.ScoreProvider {
+	return &fileProvider{dir: dir}
+}
+
+func (p *fileProvider) Name() string {
+	return ProviderFile
+}
+
+func (p *fileProvider) Fetch(ctx context.Context, ip string) (*domain.ScoreReport, error) {
+	page, err := os.ReadFile(filepath.Join(p.dir, ip+".html"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: no saved page for %s", senderscore.ErrNotFound, ip)
+		}
+		return nil, fmt.Errorf("failed to read saved page: %w", err)
+	}
+	return parseReport(p.Name(), ip, string(page))
+}
+
+// ParseArchivedReport re-runs the parser over a previously archived page.
+func ParseArchivedReport(raw *domain.RawReport) (*domain.ScoreReport, error) {
+	report, err := parseReport(raw.Source, raw.IP, string(raw.Body))
+	if err != nil {
+		return nil, err
+	}
+	report.FetchedAt = raw.FetchedAt
+	return report, nil
+}
+
+func parseReport(source, ip, page string) (*domain.ScoreReport, error) {
+	result, err := NewParser(page).Parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse report: %w", err)
+	}
+	return result.toScoreReport(source, ip), nil
+}
+
+func (r *Result) toScoreReport(source, ip string) *domain.ScoreReport {
+	report := &domain.ScoreReport{
+		IP:     ip,
+		Source: source,
+		NoData: r.Status == ReportStatusNoData,
+	}
+	if report.NoData {
+		return report
+	}
+
+	report.Score = r.SenderScore
+	report.SpamTrap = r.SpamTrap
+	report.Blocklists = r.Blocklists
+	report.Complaints = r.Complaints
+	report.BlocklistCount = r.BlocklistCount
+	report.ComplaintRate = r.ComplaintRate
+
+	for _, issue := range r.Issues {
+		report.MissingFields = append(report.MissingFields, issue.Field)
+	}
+
+	volumes := make(map[string]int)
+	for _, v := range r.SSVolume {
+		volumes[v.Timestamp] = v.Value
+	}
+
+	report.History = make([]domain.HistoryPoint, 0, len(r.SSTrend))
+	for _, p := range r.SSTrend {
+		ms, _ := strconv.ParseInt(p.Timestamp, 10, 64)
+
+		report.History = append(report.History, domain.HistoryPoint{
+			Date:     time.UnixMilli(ms),
+			Score:    p.Value,
+			Volume:   volumes[p.Timestamp],
+			SpamTrap: r.SpamTrap,
+		})
+	}
+
+	return report
+}
@@ -0,0 +1,79 @@
+package senderscore
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrRateLimited      = errors.New("rate limited by senderscore.org")
+	ErrNotFound         = errors.New("report not found on senderscore.org")
+	ErrBlocked          = errors.New("request blocked by senderscore.org")
+	ErrUpstreamDown     = errors.New("senderscore.org is unavailable")
+	ErrUnexpectedStatus = errors.New("unexpected response from senderscore.org")
+)
+
+// ResponseError describes a non-200 response. It unwraps to one of the
+// sentinel errors above so callers can use errors.Is.
+type ResponseError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Body       string
+	Err        error
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s: status %d", e.Err, e.StatusCode)
+}
+
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
+
+func newResponseError(resp *http.Response, body []byte) *ResponseError {
+	respErr := &ResponseError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		Body:       string(body),
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		respErr.Err = ErrRateLimited
+	case resp.StatusCode == http.StatusNotFound:
+		respErr.Err = ErrNotFound
+	case resp.StatusCode == http.StatusForbidden:
+		respErr.Err = ErrBlocked
+	case resp.StatusCode >= http.StatusInternalServerError:
+		respErr.Err = ErrUpstreamDown
+	default:
+		respErr.Err = ErrUnexpectedStatus
+	}
+
+	return respErr
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+func isTransient(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUpstreamDown)
+}
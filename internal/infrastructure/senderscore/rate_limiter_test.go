@@ -0,0 +1,107 @@
+package senderscore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	limiter := NewRateLimiter(20, 3)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("Wait() %d unexpected error: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("burst of 3 took %s, want no waiting", elapsed)
+	}
+
+	// Токены кончились: следующий запрос ждет около 1/rate
+	start = time.Now()
+	if err := limiter.Wait(ctx); err != nil {
+		t.Fatalf("Wait() unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Errorf("request after the burst waited %s, want about 50ms", elapsed)
+	}
+}
+
+func TestRateLimiterBurstAtLeastOne(t *testing.T) {
+	limiter := NewRateLimiter(1000, 0)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() with burst 0 unexpected error: %v", err)
+	}
+}
+
+func TestRateLimiterHonoursContext(t *testing.T) {
+	limiter := NewRateLimiter(0.01, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Wait() returned after %s, want it bounded by the context", elapsed)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() with canceled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestRateLimiterSharedBetweenGoroutines(t *testing.T) {
+	limiter := NewRateLimiter(100, 1)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Errorf("Wait() unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Первый запрос проходит сразу, остальные пять - по одному в 10ms
+	if elapsed := time.Since(start); elapsed < 45*time.Millisecond {
+		t.Errorf("6 requests at 100/s with burst 1 took %s, want at least 50ms", elapsed)
+	}
+}
+
+func TestRateLimitedClientDoesNotSendCanceledRequest(t *testing.T) {
+	client := &fakeClient{responses: []fakeResponse{{status: http.StatusOK}}}
+	limiter := NewRateLimiter(0.01, 1)
+	limited := NewRateLimitedClient(client, limiter)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://senderscore.org/report", nil)
+	if _, err := limited.Do(req); err != nil {
+		t.Fatalf("Do() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := limited.Do(req.WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Do() error = %v, want context.Canceled", err)
+	}
+	if client.calls != 1 {
+		t.Errorf("calls = %d, want the canceled request not to be sent", client.calls)
+	}
+}
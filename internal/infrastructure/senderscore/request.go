@@ -1,9 +1,13 @@
 package senderscore
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
+	"time"
 )
 
 var baseUrl = "https://senderscore.org"
@@ -12,16 +16,69 @@ type HttpClientInterface interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+}
+
 type RequestWrapper struct {
 	client HttpClientInterface
+	retry  RetryPolicy
 }
 
 func NewRequestWrapper(client HttpClientInterface) *RequestWrapper {
-	return &RequestWrapper{client: client}
+	return NewRequestWrapperWithRetry(client, DefaultRetryPolicy)
 }
 
-func (r *RequestWrapper) SendRequest(method, url string, userAgent string) ([]byte, error) {
-	req, err := http.NewRequest(method, r.buildUrl(url), nil)
+func NewRequestWrapperWithRetry(client HttpClientInterface, retry RetryPolicy) *RequestWrapper {
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+	return &RequestWrapper{client: client, retry: retry}
+}
+
+func (r *RequestWrapper) SendRequest(ctx context.Context, method, url string, userAgent string) ([]byte, error) {
+	var lastErr error
+
+	for attempt := 0; attempt < r.retry.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			delay := r.backoff(attempt, lastErr)
+			if delay < 0 {
+				break
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, fmt.Errorf("%w (last error: %w)", ctx.Err(), lastErr)
+			case <-timer.C:
+			}
+		}
+
+		body, err := r.send(ctx, method, url, userAgent)
+		if err == nil {
+			return body, nil
+		}
+
+		lastErr = err
+		if ctx.Err() != nil || !isTransient(err) {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+func (r *RequestWrapper) send(ctx context.Context, method, url string, userAgent string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, r.buildUrl(url), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -30,22 +87,42 @@ func (r *RequestWrapper) SendRequest(method, url string, userAgent string) ([]by
 
 	resp, err := r.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("network error: %w", err)
+		return nil, fmt.Errorf("%w: network error: %w", ErrUpstreamDown, err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read body: %w", err)
+		return nil, fmt.Errorf("%w: failed to read body: %w", ErrUpstreamDown, err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("server error: status %d, body %s", resp.StatusCode, string(body))
+		return nil, newResponseError(resp, body)
 	}
 
 	return body, nil
 }
 
+// backoff returns the delay before the given retry attempt, or a negative
+// duration when the server asked us to wait longer than MaxDelay.
+func (r *RequestWrapper) backoff(attempt int, lastErr error) time.Duration {
+	var respErr *ResponseError
+	if errors.As(lastErr, &respErr) && respErr.RetryAfter > 0 {
+		if respErr.RetryAfter > r.retry.MaxDelay {
+			return -1
+		}
+		return respErr.RetryAfter
+	}
+
+	delay := r.retry.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > r.retry.MaxDelay {
+		delay = r.retry.MaxDelay
+	}
+
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
 func (r *RequestWrapper) buildUrl(url string) string {
 	return fmt.Sprintf("%s/%s", baseUrl, url)
 }
@@ -0,0 +1,185 @@
+package senderscore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeResponse struct {
+	status     int
+	body       string
+	retryAfter string
+	err        error
+}
+
+// fakeClient returns the queued responses in order and repeats the last one.
+type fakeClient struct {
+	responses []fakeResponse
+	calls     int
+}
+
+func (c *fakeClient) Do(req *http.Request) (*http.Response, error) {
+	resp := c.responses[min(c.calls, len(c.responses)-1)]
+	c.calls++
+	if resp.err != nil {
+		return nil, resp.err
+	}
+
+	header := http.Header{}
+	if resp.retryAfter != "" {
+		header.Set("Retry-After", resp.retryAfter)
+	}
+	return &http.Response{
+		StatusCode: resp.status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(resp.body)),
+	}, nil
+}
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    10 * time.Millisecond,
+}
+
+func TestSendRequestRetriesTransientErrors(t *testing.T) {
+	client := &fakeClient{responses: []fakeResponse{
+		{status: http.StatusServiceUnavailable},
+		{err: errors.New("connection reset")},
+		{status: http.StatusOK, body: "report"},
+	}}
+	rw := NewRequestWrapperWithRetry(client, testRetryPolicy)
+
+	body, err := rw.SendRequest(context.Background(), http.MethodGet, "report", "test")
+	if err != nil {
+		t.Fatalf("SendRequest() unexpected error: %v", err)
+	}
+	if string(body) != "report" {
+		t.Errorf("body = %q, want report", body)
+	}
+	if client.calls != 3 {
+		t.Errorf("calls = %d, want 3", client.calls)
+	}
+}
+
+func TestSendRequestStopsAfterMaxAttempts(t *testing.T) {
+	client := &fakeClient{responses: []fakeResponse{{status: http.StatusTooManyRequests}}}
+	rw := NewRequestWrapperWithRetry(client, testRetryPolicy)
+
+	_, err := rw.SendRequest(context.Background(), http.MethodGet, "report", "test")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("error = %v, want ErrRateLimited", err)
+	}
+	if client.calls != testRetryPolicy.MaxAttempts {
+		t.Errorf("calls = %d, want %d", client.calls, testRetryPolicy.MaxAttempts)
+	}
+}
+
+func TestSendRequestClassifiesErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  fakeResponse
+		want      error
+		wantCalls int
+	}{
+		{name: "not found", response: fakeResponse{status: http.StatusNotFound}, want: ErrNotFound, wantCalls: 1},
+		{name: "forbidden", response: fakeResponse{status: http.StatusForbidden}, want: ErrBlocked, wantCalls: 1},
+		{name: "unexpected status", response: fakeResponse{status: http.StatusBadRequest}, want: ErrUnexpectedStatus, wantCalls: 1},
+		{name: "rate limited", response: fakeResponse{status: http.StatusTooManyRequests}, want: ErrRateLimited, wantCalls: 3},
+		{name: "server error", response: fakeResponse{status: http.StatusBadGateway}, want: ErrUpstreamDown, wantCalls: 3},
+		{name: "network error", response: fakeResponse{err: errors.New("dial tcp: timeout")}, want: ErrUpstreamDown, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{responses: []fakeResponse{tt.response}}
+			rw := NewRequestWrapperWithRetry(client, testRetryPolicy)
+
+			_, err := rw.SendRequest(context.Background(), http.MethodGet, "report", "test")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+			if client.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", client.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestSendRequestGivesUpOnLongRetryAfter(t *testing.T) {
+	client := &fakeClient{responses: []fakeResponse{
+		{status: http.StatusTooManyRequests, retryAfter: "120"},
+		{status: http.StatusOK, body: "report"},
+	}}
+	rw := NewRequestWrapperWithRetry(client, testRetryPolicy)
+
+	_, err := rw.SendRequest(context.Background(), http.MethodGet, "report", "test")
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) || respErr.RetryAfter != 120*time.Second {
+		t.Fatalf("error = %v, want ResponseError with Retry-After 120s", err)
+	}
+	if client.calls != 1 {
+		t.Errorf("calls = %d, want 1 when Retry-After exceeds MaxDelay", client.calls)
+	}
+}
+
+func TestSendRequestHonoursContext(t *testing.T) {
+	client := &fakeClient{responses: []fakeResponse{{status: http.StatusServiceUnavailable}}}
+	rw := NewRequestWrapperWithRetry(client, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := rw.SendRequest(ctx, http.MethodGet, "report", "test")
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrUpstreamDown) {
+		t.Fatalf("error = %v, want deadline exceeded wrapping the last error", err)
+	}
+	if client.calls != 1 {
+		t.Errorf("calls = %d, want 1", client.calls)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	rw := NewRequestWrapperWithRetry(nil, RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 5 * time.Second})
+	transient := &ResponseError{StatusCode: http.StatusServiceUnavailable, Err: ErrUpstreamDown}
+
+	for attempt := 1; attempt <= 8; attempt++ {
+		ceiling := min(time.Second<<(attempt-1), 5*time.Second)
+		delay := rw.backoff(attempt, transient)
+		if delay < ceiling/2 || delay > ceiling {
+			t.Errorf("backoff(%d) = %s, want between %s and %s", attempt, delay, ceiling/2, ceiling)
+		}
+	}
+
+	// Сдвиг на большое число попыток не должен переполнять задержку
+	if delay := rw.backoff(70, transient); delay <= 0 || delay > 5*time.Second {
+		t.Errorf("backoff(70) = %s, want capped at MaxDelay", delay)
+	}
+
+	withRetryAfter := &ResponseError{StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second, Err: ErrRateLimited}
+	if delay := rw.backoff(1, withRetryAfter); delay != 3*time.Second {
+		t.Errorf("backoff with Retry-After 3s = %s, want 3s", delay)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("7"); got != 7*time.Second {
+		t.Errorf("parseRetryAfter(7) = %s, want 7s", got)
+	}
+	if got := parseRetryAfter(""); got != 0 {
+		t.Errorf("parseRetryAfter(\"\") = %s, want 0", got)
+	}
+	if got := parseRetryAfter("soon"); got != 0 {
+		t.Errorf("parseRetryAfter(soon) = %s, want 0", got)
+	}
+
+	at := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(at); got <= 50*time.Second || got > time.Minute {
+		t.Errorf("parseRetryAfter(%q) = %s, want about one minute", at, got)
+	}
+}
@@ -1,6 +1,7 @@
 package senderscore
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -18,9 +19,9 @@ func NewSenderClient(rw *RequestWrapper) *SenderClient {
 	}
 }
 
-func (c *SenderClient) GetReport(ip string) (string, error) {
+func (c *SenderClient) GetReport(ctx context.Context, ip string) (string, error) {
 	url := fmt.Sprintf(reportPath, ip)
-	htmlContent, err := c.request.SendRequest("GET", url, c.userAgent)
+	htmlContent, err := c.request.SendRequest(ctx, "GET", url, c.userAgent)
 	if err != nil {
 		return "", err
 	}
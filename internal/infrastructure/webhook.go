@@ -0,0 +1,127 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+)
+
+const (
+	// Подпись тела запроса: "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+	WebhookSignatureHeader = "X-SenderScore-Signature"
+	WebhookTimestampHeader = "X-SenderScore-Timestamp"
+
+	webhookChannelKey = "webhook"
+)
+
+type webhookNotifier struct {
+	urls   []string
+	secret []byte
+	client *http.Client
+}
+
+func NewWebhookNotifier(urls []string, secret string, client *http.Client) domain.Notifier {
+	return &webhookNotifier{
+		urls:   urls,
+		secret: []byte(secret),
+		client: client,
+	}
+}
+
+type webhookPayload struct {
+	Rule               string `json:"rule"`
+	IP                 string `json:"ip"`
+	GroupIDs           []int  `json:"group_ids"`
+	Score              int    `json:"score"`
+	PreviousScore      int    `json:"previous_score"`
+	SpamTrap           int    `json:"spam_trap"`
+	Blocklists         string `json:"blocklists"`
+	PreviousBlocklists string `json:"previous_blocklists"`
+	Message            string `json:"message"`
+	TriggeredAt        int64  `json:"triggered_at"`
+}
+
+// Notify отправляет алерт на все настроенные URL, кроме уже получивших его.
+// Ошибка возвращается, если хотя бы один из них не принял запрос.
+func (n *webhookNotifier) Notify(ctx context.Context, alert *domain.Alert, delivered map[string]bool) error {
+	if len(n.urls) == 0 {
+		return domain.ErrNoChannels
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		Rule:               alert.Rule,
+		IP:                 alert.IP,
+		GroupIDs:           alert.GroupIDs,
+		Score:              alert.Score,
+		PreviousScore:      alert.PreviousScore,
+		SpamTrap:           alert.SpamTrap,
+		Blocklists:         alert.Blocklists,
+		PreviousBlocklists: alert.PreviousBlocklists,
+		Message:            alert.Message,
+		TriggeredAt:        alert.TriggeredAt.Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	var errs []error
+	for _, url := range n.urls {
+		key := webhookChannelKey + ":" + url
+		if delivered[key] {
+			continue
+		}
+		if err := n.send(ctx, url, body); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", url, err))
+			continue
+		}
+		delivered[key] = true
+	}
+
+	return errors.Join(errs...)
+}
+
+func (n *webhookNotifier) send(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Подписывается время отправки, а не срабатывания: повторная доставка
+	// старого алерта не должна выглядеть для получателя как replay
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, n.sign(timestamp, body))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (n *webhookNotifier) sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, n.secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
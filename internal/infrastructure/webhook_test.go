@@ -0,0 +1,108 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifierNotify(t *testing.T) {
+	const secret = "s3cret"
+	alert := testAlert()
+
+	var calls int32
+	start := time.Now().Unix()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read body: %v", err)
+			return
+		}
+
+		timestamp := r.Header.Get(WebhookTimestampHeader)
+		sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || sentAt < start || sentAt > time.Now().Unix() {
+			t.Errorf("%s = %q, want send time", WebhookTimestampHeader, timestamp)
+		}
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "." + string(body)))
+		want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if got := r.Header.Get(WebhookSignatureHeader); !hmac.Equal([]byte(got), []byte(want)) {
+			t.Errorf("%s = %q, want %q", WebhookSignatureHeader, got, want)
+		}
+
+		var payload webhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+			return
+		}
+		if payload.Rule != alert.Rule || payload.IP != alert.IP || payload.Score != alert.Score ||
+			payload.PreviousScore != alert.PreviousScore || payload.Blocklists != alert.Blocklists ||
+			payload.TriggeredAt != alert.TriggeredAt.Unix() || len(payload.GroupIDs) != len(alert.GroupIDs) {
+			t.Errorf("payload = %+v, does not match alert %+v", payload, alert)
+		}
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier([]string{server.URL + "/a", server.URL + "/b"}, secret, server.Client())
+	if err := notifier.Notify(context.Background(), alert, make(map[string]bool)); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("webhook called %d times, want 2", calls)
+	}
+}
+
+func TestWebhookNotifierSignatureChangesWithSecret(t *testing.T) {
+	body := []byte(`{"ip":"192.0.2.10"}`)
+	a := &webhookNotifier{secret: []byte("one")}
+	b := &webhookNotifier{secret: []byte("two")}
+
+	if a.sign("1", body) == b.sign("1", body) {
+		t.Error("signatures with different secrets are equal")
+	}
+	if a.sign("1", body) == a.sign("2", body) {
+		t.Error("signatures with different timestamps are equal")
+	}
+}
+
+func TestWebhookNotifierRetriesOnlyFailedURL(t *testing.T) {
+	var okCalls, failingCalls int32
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&okCalls, 1)
+	}))
+	defer ok.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&failingCalls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	notifier := NewWebhookNotifier([]string{ok.URL, failing.URL}, "secret", http.DefaultClient)
+	delivered := make(map[string]bool)
+	if err := notifier.Notify(context.Background(), testAlert(), delivered); err == nil {
+		t.Fatal("Notify() error = nil, want error for failing URL")
+	}
+	if !delivered["webhook:"+ok.URL] || delivered["webhook:"+failing.URL] {
+		t.Fatalf("delivered = %v, want only %s", delivered, ok.URL)
+	}
+
+	if err := notifier.Notify(context.Background(), testAlert(), delivered); err == nil {
+		t.Fatal("second Notify() error = nil, want error for failing URL")
+	}
+	if okCalls != 1 || failingCalls != 2 {
+		t.Errorf("calls ok=%d failing=%d, want 1 and 2", okCalls, failingCalls)
+	}
+}
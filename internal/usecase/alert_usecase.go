@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Alerter проверяет правила алертов после сохранения свежего отчета по IP.
+type Alerter interface {
+	// Evaluate сравнивает новое состояние IP с предыдущим (nil для нового IP)
+	// и отправляет уведомления по сработавшим правилам. Ошибки отправки
+	// не прерывают обработку отчета и только логируются.
+	Evaluate(ctx context.Context, current, previous *domain.IP)
+}
+
+type alerter struct {
+	rules          domain.AlertRules
+	alertStateRepo domain.AlertStateRepository
+	notifier       domain.Notifier
+}
+
+func NewAlerter(
+	rules domain.AlertRules,
+	alertStateRepo domain.AlertStateRepository,
+	notifier domain.Notifier,
+) Alerter {
+	return &alerter{
+		rules:          rules,
+		alertStateRepo: alertStateRepo,
+		notifier:       notifier,
+	}
+}
+
+func (a *alerter) Evaluate(ctx context.Context, current, previous *domain.IP) {
+	for _, check := range a.checks(current, previous) {
+		logger := logrus.WithFields(logrus.Fields{
+			"ip":   current.IP,
+			"rule": check.rule,
+		})
+
+		if !check.triggered {
+			if err := a.alertStateRepo.Resolve(ctx, current.ID, check.rule); err != nil {
+				logger.WithError(err).Warn("Failed to resolve alert")
+			}
+			continue
+		}
+
+		state, err := a.alertStateRepo.Activate(ctx, current.ID, check.rule)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to activate alert")
+			continue
+		}
+		if state.Completed {
+			continue
+		}
+
+		alert := &domain.Alert{
+			Rule:        check.rule,
+			IP:          current.IP,
+			GroupIDs:    current.GroupIDs,
+			Score:       current.Score,
+			SpamTrap:    current.SpamTrap,
+			Blocklists:  current.Blocklists,
+			Message:     check.message,
+			TriggeredAt: state.TriggeredAt,
+		}
+		if previous != nil {
+			alert.PreviousScore = previous.Score
+			alert.PreviousBlocklists = previous.Blocklists
+		}
+
+		delivered := make(map[string]bool, len(state.Delivered))
+		for _, key := range state.Delivered {
+			delivered[key] = true
+		}
+
+		// Состояние остается активным, пока алерт не доставлен во все каналы:
+		// при следующем обновлении он уйдет только в каналы, где отправка не удалась
+		sendErr := a.notifier.Notify(ctx, alert, delivered)
+		state.Delivered = state.Delivered[:0]
+		for key := range delivered {
+			state.Delivered = append(state.Delivered, key)
+		}
+		sort.Strings(state.Delivered)
+		state.Completed = sendErr == nil
+		if err := a.alertStateRepo.SaveDelivery(ctx, current.ID, state); err != nil {
+			logger.WithError(err).Warn("Failed to save alert delivery")
+		}
+
+		switch {
+		case errors.Is(sendErr, domain.ErrNoChannels):
+			logger.Debug("No notification channels for alert")
+		case sendErr != nil:
+			logger.WithError(sendErr).Error("Failed to send alert")
+		default:
+			logger.Info("Alert sent")
+		}
+	}
+}
+
+type alertCheck struct {
+	rule      string
+	triggered bool
+	message   string
+}
+
+func (a *alerter) checks(current, previous *domain.IP) []alertCheck {
+	var checks []alertCheck
+
+	if a.rules.ScoreBelow > 0 {
+		checks = append(checks, alertCheck{
+			rule:      domain.AlertRuleScoreBelow,
+			triggered: current.Score < a.rules.ScoreBelow,
+			message:   fmt.Sprintf("Sender score %d is below %d", current.Score, a.rules.ScoreBelow),
+		})
+	}
+
+	if a.rules.ScoreDrop > 0 && previous != nil && previous.Status == domain.IPStatusOK {
+		drop := previous.Score - current.Score
+		checks = append(checks, alertCheck{
+			rule:      domain.AlertRuleScoreDrop,
+			triggered: drop > a.rules.ScoreDrop,
+			message:   fmt.Sprintf("Sender score dropped by %d points (%d -> %d)", drop, previous.Score, current.Score),
+		})
+	}
+
+	if a.rules.SpamTraps {
+		checks = append(checks, alertCheck{
+			rule:      domain.AlertRuleSpamTraps,
+			triggered: current.SpamTrap > 0,
+			message:   fmt.Sprintf("%d spam trap hits", current.SpamTrap),
+		})
+	}
+
+	if a.rules.Blocklisted {
+		checks = append(checks, alertCheck{
+			rule:      domain.AlertRuleBlocklisted,
+			triggered: current.IsBlocklisted(),
+			message:   fmt.Sprintf("IP is listed on blocklists: %s", current.Blocklists),
+		})
+	}
+
+	return checks
+}
+
+type groupChannelNotifier struct {
+	channelRepo domain.NotificationChannelRepository
+	senders     map[string]domain.ChannelSender
+}
+
+// NewGroupChannelNotifier доставляет алерт во все каналы групп, в которые
+// входит IP. Каналы типа без sender пропускаются. Если у IP нет ни одного
+// канала, возвращается domain.ErrNoChannels.
+func NewGroupChannelNotifier(
+	channelRepo domain.NotificationChannelRepository,
+	senders map[string]domain.ChannelSender,
+) domain.Notifier {
+	return &groupChannelNotifier{
+		channelRepo: channelRepo,
+		senders:     senders,
+	}
+}
+
+func (n *groupChannelNotifier) Notify(ctx context.Context, alert *domain.Alert, delivered map[string]bool) error {
+	channels, err := n.channelRepo.ListByGroupIDs(ctx, alert.GroupIDs)
+	if err != nil {
+		return err
+	}
+
+	// Один и тот же канал может быть настроен в нескольких группах IP
+	seen := make(map[string]bool)
+	found := false
+	var errs []error
+	for _, channel := range channels {
+		key := channel.Type + ":" + channel.Target
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		sender, ok := n.senders[channel.Type]
+		if !ok {
+			logrus.WithFields(logrus.Fields{
+				"group_id": channel.GroupID,
+				"type":     channel.Type,
+			}).Warn("Notification channel type is not configured, skipping")
+			continue
+		}
+
+		found = true
+		if delivered[key] {
+			continue
+		}
+		if err := sender.Send(ctx, channel.Target, alert); err != nil {
+			errs = append(errs, fmt.Errorf("%s channel %d: %w", channel.Type, channel.ID, err))
+			continue
+		}
+		delivered[key] = true
+	}
+
+	if !found {
+		return domain.ErrNoChannels
+	}
+	return errors.Join(errs...)
+}
+
+type multiNotifier []domain.Notifier
+
+func NewMultiNotifier(notifiers ...domain.Notifier) domain.Notifier {
+	return multiNotifier(notifiers)
+}
+
+// Notify возвращает domain.ErrNoChannels, только если каналов нет ни у одного
+// из notifier.
+func (m multiNotifier) Notify(ctx context.Context, alert *domain.Alert, delivered map[string]bool) error {
+	found := false
+	var errs []error
+	for _, notifier := range m {
+		err := notifier.Notify(ctx, alert, delivered)
+		if errors.Is(err, domain.ErrNoChannels) {
+			continue
+		}
+		found = true
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if !found {
+		return domain.ErrNoChannels
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+)
+
+type fakeAlertStateRepository struct {
+	states map[string]*domain.AlertState
+}
+
+func newFakeAlertStateRepository() *fakeAlertStateRepository {
+	return &fakeAlertStateRepository{states: make(map[string]*domain.AlertState)}
+}
+
+func (r *fakeAlertStateRepository) Activate(ctx context.Context, ipID uint, rule string) (*domain.AlertState, error) {
+	state, ok := r.states[rule]
+	if !ok {
+		state = &domain.AlertState{Rule: rule, TriggeredAt: time.Now()}
+		r.states[rule] = state
+	}
+	copied := *state
+	copied.Delivered = append([]string(nil), state.Delivered...)
+	return &copied, nil
+}
+
+func (r *fakeAlertStateRepository) SaveDelivery(ctx context.Context, ipID uint, state *domain.AlertState) error {
+	saved := *state
+	saved.Delivered = append([]string(nil), state.Delivered...)
+	r.states[state.Rule] = &saved
+	return nil
+}
+
+func (r *fakeAlertStateRepository) Resolve(ctx context.Context, ipID uint, rule string) error {
+	delete(r.states, rule)
+	return nil
+}
+
+// fakeNotifier доставляет алерт в каналы channels, кроме перечисленных в failing.
+type fakeNotifier struct {
+	channels []string
+	failing  map[string]bool
+	sent     []string
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, alert *domain.Alert, delivered map[string]bool) error {
+	if len(n.channels) == 0 {
+		return domain.ErrNoChannels
+	}
+
+	var errs []error
+	for _, key := range n.channels {
+		if delivered[key] {
+			continue
+		}
+		if n.failing[key] {
+			errs = append(errs, errors.New(key+" failed"))
+			continue
+		}
+		n.sent = append(n.sent, key)
+		delivered[key] = true
+	}
+	return errors.Join(errs...)
+}
+
+func newTestAlerter(notifier domain.Notifier) (*alerter, *fakeAlertStateRepository) {
+	repo := newFakeAlertStateRepository()
+	rules := domain.AlertRules{ScoreBelow: 70}
+	return NewAlerter(rules, repo, notifier).(*alerter), repo
+}
+
+func TestAlerterDeduplicatesUntilResolved(t *testing.T) {
+	notifier := &fakeNotifier{channels: []string{"webhook:a", "chat:b"}}
+	a, repo := newTestAlerter(notifier)
+	ctx := context.Background()
+	low := &domain.IP{ID: 1, IP: "192.0.2.1", Score: 50, Status: domain.IPStatusOK}
+
+	a.Evaluate(ctx, low, nil)
+	if len(notifier.sent) != 2 {
+		t.Fatalf("first trigger sent %v, want both channels", notifier.sent)
+	}
+	if state := repo.states[domain.AlertRuleScoreBelow]; state == nil || !state.Completed {
+		t.Fatalf("state = %+v, want completed", state)
+	}
+
+	a.Evaluate(ctx, low, low)
+	if len(notifier.sent) != 2 {
+		t.Fatalf("active alert was sent again: %v", notifier.sent)
+	}
+
+	recovered := &domain.IP{ID: 1, IP: "192.0.2.1", Score: 90, Status: domain.IPStatusOK}
+	a.Evaluate(ctx, recovered, low)
+	if _, ok := repo.states[domain.AlertRuleScoreBelow]; ok {
+		t.Fatal("state was not resolved after recovery")
+	}
+
+	a.Evaluate(ctx, low, recovered)
+	if len(notifier.sent) != 4 {
+		t.Fatalf("alert after resolve sent %v, want both channels again", notifier.sent)
+	}
+}
+
+func TestAlerterRetriesOnlyFailedChannels(t *testing.T) {
+	notifier := &fakeNotifier{
+		channels: []string{"webhook:a", "chat:b"},
+		failing:  map[string]bool{"chat:b": true},
+	}
+	a, repo := newTestAlerter(notifier)
+	ctx := context.Background()
+	low := &domain.IP{ID: 1, IP: "192.0.2.1", Score: 50, Status: domain.IPStatusOK}
+
+	a.Evaluate(ctx, low, nil)
+	state := repo.states[domain.AlertRuleScoreBelow]
+	if state == nil || state.Completed {
+		t.Fatalf("state = %+v, want active and not completed", state)
+	}
+	if len(state.Delivered) != 1 || state.Delivered[0] != "webhook:a" {
+		t.Fatalf("delivered = %v, want [webhook:a]", state.Delivered)
+	}
+
+	notifier.failing = nil
+	a.Evaluate(ctx, low, low)
+	if len(notifier.sent) != 2 || notifier.sent[1] != "chat:b" {
+		t.Fatalf("sent = %v, want retry only to chat:b", notifier.sent)
+	}
+	if state := repo.states[domain.AlertRuleScoreBelow]; !state.Completed {
+		t.Fatalf("state = %+v, want completed after retry", state)
+	}
+}
+
+func TestAlerterKeepsAlertWithoutChannels(t *testing.T) {
+	notifier := &fakeNotifier{}
+	a, repo := newTestAlerter(notifier)
+	ctx := context.Background()
+	low := &domain.IP{ID: 1, IP: "192.0.2.1", Score: 50, Status: domain.IPStatusOK}
+
+	a.Evaluate(ctx, low, nil)
+	if state := repo.states[domain.AlertRuleScoreBelow]; state == nil || state.Completed {
+		t.Fatalf("state = %+v, want active and not completed", state)
+	}
+
+	notifier.channels = []string{"chat:b"}
+	a.Evaluate(ctx, low, low)
+	if len(notifier.sent) != 1 {
+		t.Fatalf("sent = %v, want delivery once a channel exists", notifier.sent)
+	}
+}
+
+type fakeChannelRepository struct {
+	domain.NotificationChannelRepository
+	channels []*domain.NotificationChannel
+}
+
+func (r *fakeChannelRepository) ListByGroupIDs(ctx context.Context, groupIDs []int) ([]*domain.NotificationChannel, error) {
+	return r.channels, nil
+}
+
+type fakeChannelSender struct {
+	targets []string
+	err     error
+}
+
+func (s *fakeChannelSender) Send(ctx context.Context, target string, alert *domain.Alert) error {
+	s.targets = append(s.targets, target)
+	return s.err
+}
+
+func TestGroupChannelNotifierNotify(t *testing.T) {
+	chat := &fakeChannelSender{}
+	repo := &fakeChannelRepository{channels: []*domain.NotificationChannel{
+		{ID: 1, GroupID: 1, Type: domain.ChannelTypeChat, Target: "https://chat/1"},
+		{ID: 2, GroupID: 2, Type: domain.ChannelTypeChat, Target: "https://chat/1"},
+		{ID: 3, GroupID: 2, Type: domain.ChannelTypeChat, Target: "https://chat/2"},
+		{ID: 4, GroupID: 2, Type: domain.ChannelTypeEmail, Target: "owner@example.com"},
+	}}
+	notifier := NewGroupChannelNotifier(repo, map[string]domain.ChannelSender{domain.ChannelTypeChat: chat})
+
+	delivered := map[string]bool{"chat:https://chat/2": true}
+	if err := notifier.Notify(context.Background(), &domain.Alert{}, delivered); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+	if len(chat.targets) != 1 || chat.targets[0] != "https://chat/1" {
+		t.Errorf("sent to %v, want only https://chat/1", chat.targets)
+	}
+	if !delivered["chat:https://chat/1"] {
+		t.Errorf("delivered = %v, want chat:https://chat/1", delivered)
+	}
+
+	empty := NewGroupChannelNotifier(&fakeChannelRepository{}, nil)
+	if err := empty.Notify(context.Background(), &domain.Alert{}, map[string]bool{}); !errors.Is(err, domain.ErrNoChannels) {
+		t.Errorf("Notify() without channels error = %v, want ErrNoChannels", err)
+	}
+}
+
+func TestMultiNotifierNoChannels(t *testing.T) {
+	empty := &fakeNotifier{}
+	withChannel := &fakeNotifier{channels: []string{"webhook:a"}}
+
+	if err := NewMultiNotifier(empty, empty).Notify(context.Background(), &domain.Alert{}, map[string]bool{}); !errors.Is(err, domain.ErrNoChannels) {
+		t.Errorf("error = %v, want ErrNoChannels", err)
+	}
+	if err := NewMultiNotifier(empty, withChannel).Notify(context.Background(), &domain.Alert{}, map[string]bool{}); err != nil {
+		t.Errorf("error = %v, want nil when one notifier delivered", err)
+	}
+}
@@ -1,5 +1,7 @@
 package usecase
 
+import "time"
+
 type CreateGroupDTO struct {
 	GroupID   int
 	GroupName string
@@ -12,6 +14,14 @@ type GroupDTO struct {
 	SpamTrapCount int
 	IPsCount      int
 	IPs           []IPDTO
+
+	AvgScore           float64
+	MinScore           int
+	WeightedScore      float64
+	LowScoreCount      int
+	CriticalScoreCount int
+	BlocklistedCount   int
+	AvgComplaintRate   float64
 }
 
 type IPDTO struct {
@@ -21,13 +31,47 @@ type IPDTO struct {
 	SpamTrap   int
 	Blocklists string
 	Complaints string
+	Status     string
 	UpdatedAt  int64
+	GroupIDs   []int
+
+	BlocklistCount int
+	ComplaintRate  float64
+}
+
+type GroupFilterDTO struct {
+	Search       string
+	MinSpamTraps *int
+	MaxSpamTraps *int
+	MinIPs       *int
+	SortBy       string
+	SortDesc     bool
+}
+
+type IPFilterDTO struct {
+	GroupID      int
+	Search       string
+	Status       string
+	MinScore     *int
+	MaxScore     *int
+	HasSpamTraps *bool
+
+	Blocklisted      *bool
+	MinComplaintRate *float64
+}
+
+type ScoreStatDTO struct {
+	ID     uint
+	Score  int
+	Result int
+	Date   string
 }
 
 type AddIPDTO struct {
 	GroupID   int
 	GroupName string
 	IP        string
+	CIDR      string
 }
 
 type HistoryEntryDTO struct {
@@ -37,13 +81,28 @@ type HistoryEntryDTO struct {
 	SpamTrap int
 }
 
+type GroupHistoryPointDTO struct {
+	Date     string
+	AvgScore float64
+	MinScore int
+	Volume   int
+	SpamTrap int
+	IPsCount int
+}
+
 type SubmitScoreDTO struct {
 	IP         string
+	NoData     bool
 	Score      int
 	SpamTrap   int
 	Blocklists string
 	Complaints string
 	History    []HistoryEntryDTO
+	// ObservedAt is when the report was fetched. Zero means now.
+	ObservedAt time.Time
+
+	BlocklistCount int
+	ComplaintRate  float64
 }
 
 type SubmitScoreResultDTO struct {
@@ -55,14 +114,62 @@ type SubmitScoreResultDTO struct {
 	HistoryUpdated int
 }
 
+const (
+	AddIPStatusCreated = "created"
+	AddIPStatusLinked  = "linked"
+	AddIPStatusInvalid = "invalid"
+	AddIPStatusFailed  = "failed"
+)
+
+type AddIPItemResultDTO struct {
+	Index   int
+	IP      string
+	GroupID int
+	Status  string
+	Reason  string
+}
+
 type BatchIPResultDTO struct {
 	GroupsCreated int
 	IPsCreated    int
 	IPsSkipped    int
+	IPsInvalid    int
+	IPsFailed     int
 	Message       string
+	Items         []AddIPItemResultDTO
 }
 
 type PaginationDTO struct {
 	Page     int
 	PageSize int
 }
+
+// Normalize replaces an out of range page or page size with the defaults.
+func (p PaginationDTO) Normalize() PaginationDTO {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PageSize < 1 || p.PageSize > 100 {
+		p.PageSize = 20
+	}
+	return p
+}
+
+func (p PaginationDTO) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+type NotificationChannelDTO struct {
+	ID      uint
+	GroupID int
+	Type    string
+	Target  string
+}
+
+type IPEventDTO struct {
+	ID        uint
+	Field     string
+	OldValue  string
+	NewValue  string
+	CreatedAt int64
+}
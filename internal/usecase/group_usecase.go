@@ -3,6 +3,9 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
 
 	"git.emercury.dev/emercury/senderscore/api/internal/domain"
 )
@@ -11,10 +14,14 @@ type GroupUseCase interface {
 	CreateGroup(ctx context.Context, dto CreateGroupDTO) (*GroupDTO, error)
 	GetGroupByID(ctx context.Context, id uint, withIPs bool) (*GroupDTO, error)
 	GetGroupByGroupID(ctx context.Context, groupID int, withIPs bool) (*GroupDTO, error)
-	ListGroups(ctx context.Context, pagination PaginationDTO, withIPs bool) ([]*GroupDTO, int64, error)
+	ListGroups(ctx context.Context, filter GroupFilterDTO, pagination PaginationDTO, withIPs bool) ([]*GroupDTO, int64, error)
 	UpdateCounters(ctx context.Context, groupID int) error
 	UpdateGroupName(ctx context.Context, groupID int, newName string) error
 	DeleteGroup(ctx context.Context, groupID int) error
+	GetGroupHistory(ctx context.Context, groupID int, from, to time.Time) ([]GroupHistoryPointDTO, error)
+	AddChannel(ctx context.Context, dto NotificationChannelDTO) (*NotificationChannelDTO, error)
+	ListChannels(ctx context.Context, groupID int) ([]*NotificationChannelDTO, error)
+	DeleteChannel(ctx context.Context, groupID int, id uint) error
 }
 
 type groupUseCase struct {
@@ -22,6 +29,7 @@ type groupUseCase struct {
 	ipRepo        domain.IPRepository
 	historyRepo   domain.HistoryRepository
 	scoreStatRepo domain.ScoreStatRepository
+	channelRepo   domain.NotificationChannelRepository
 }
 
 func NewGroupUseCase(
@@ -29,12 +37,14 @@ func NewGroupUseCase(
 	ipRepo domain.IPRepository,
 	historyRepo domain.HistoryRepository,
 	scoreStatRepo domain.ScoreStatRepository,
+	channelRepo domain.NotificationChannelRepository,
 ) GroupUseCase {
 	return &groupUseCase{
 		groupRepo:     groupRepo,
 		ipRepo:        ipRepo,
 		historyRepo:   historyRepo,
 		scoreStatRepo: scoreStatRepo,
+		channelRepo:   channelRepo,
 	}
 }
 
@@ -95,16 +105,24 @@ func (uc *groupUseCase) GetGroupByGroupID(ctx context.Context, groupID int, with
 	return uc.mapGroupToDTO(group, ips), nil
 }
 
-func (uc *groupUseCase) ListGroups(ctx context.Context, pagination PaginationDTO, withIPs bool) ([]*GroupDTO, int64, error) {
-	if pagination.Page < 1 {
-		pagination.Page = 1
+func (uc *groupUseCase) ListGroups(ctx context.Context, filter GroupFilterDTO, pagination PaginationDTO, withIPs bool) ([]*GroupDTO, int64, error) {
+	pagination = pagination.Normalize()
+
+	if filter.SortBy == "" {
+		filter.SortBy = domain.GroupSortGroupID
 	}
-	if pagination.PageSize < 1 || pagination.PageSize > 100 {
-		pagination.PageSize = 20
+	if !domain.IsValidGroupSort(filter.SortBy) {
+		return nil, 0, domain.ErrInvalidGroupSort
 	}
 
-	offset := (pagination.Page - 1) * pagination.PageSize
-	groups, total, err := uc.groupRepo.List(ctx, offset, pagination.PageSize)
+	groups, total, err := uc.groupRepo.List(ctx, domain.GroupFilter{
+		Search:       filter.Search,
+		MinSpamTraps: filter.MinSpamTraps,
+		MaxSpamTraps: filter.MaxSpamTraps,
+		MinIPs:       filter.MinIPs,
+		SortBy:       filter.SortBy,
+		SortDesc:     filter.SortDesc,
+	}, pagination.Offset(), pagination.PageSize)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list groups: %w", err)
 	}
@@ -185,6 +203,99 @@ func (uc *groupUseCase) DeleteGroup(ctx context.Context, groupID int) error {
 	return nil
 }
 
+func (uc *groupUseCase) GetGroupHistory(ctx context.Context, groupID int, from, to time.Time) ([]GroupHistoryPointDTO, error) {
+	group, err := uc.groupRepo.GetByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := uc.historyRepo.AggregateByGroup(ctx, group.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]GroupHistoryPointDTO, len(points))
+	for i, point := range points {
+		result[i] = GroupHistoryPointDTO{
+			Date:     point.Date.Format("02.01.2006"),
+			AvgScore: point.AvgScore,
+			MinScore: point.MinScore,
+			Volume:   point.Volume,
+			SpamTrap: point.SpamTrap,
+			IPsCount: point.IPsCount,
+		}
+	}
+	return result, nil
+}
+
+func (uc *groupUseCase) AddChannel(ctx context.Context, dto NotificationChannelDTO) (*NotificationChannelDTO, error) {
+	if err := validateChannel(dto.Type, dto.Target); err != nil {
+		return nil, err
+	}
+
+	channel := &domain.NotificationChannel{
+		GroupID: dto.GroupID,
+		Type:    dto.Type,
+		Target:  dto.Target,
+	}
+	if err := uc.channelRepo.Create(ctx, channel); err != nil {
+		return nil, err
+	}
+
+	return mapChannelToDTO(channel), nil
+}
+
+func (uc *groupUseCase) ListChannels(ctx context.Context, groupID int) ([]*NotificationChannelDTO, error) {
+	if _, err := uc.groupRepo.GetByGroupID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	channels, err := uc.channelRepo.ListByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*NotificationChannelDTO, len(channels))
+	for i, channel := range channels {
+		result[i] = mapChannelToDTO(channel)
+	}
+	return result, nil
+}
+
+func (uc *groupUseCase) DeleteChannel(ctx context.Context, groupID int, id uint) error {
+	if _, err := uc.groupRepo.GetByGroupID(ctx, groupID); err != nil {
+		return err
+	}
+	return uc.channelRepo.Delete(ctx, groupID, id)
+}
+
+func validateChannel(channelType, target string) error {
+	switch channelType {
+	case domain.ChannelTypeChat:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: target must be an http(s) URL", domain.ErrInvalidChannel)
+		}
+	case domain.ChannelTypeEmail:
+		addr, err := mail.ParseAddress(target)
+		if err != nil || addr.Address != target {
+			return fmt.Errorf("%w: target must be a plain email address", domain.ErrInvalidChannel)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", domain.ErrInvalidChannel, channelType)
+	}
+	return nil
+}
+
+func mapChannelToDTO(channel *domain.NotificationChannel) *NotificationChannelDTO {
+	return &NotificationChannelDTO{
+		ID:      channel.ID,
+		GroupID: channel.GroupID,
+		Type:    channel.Type,
+		Target:  channel.Target,
+	}
+}
+
 func (uc *groupUseCase) mapGroupToDTO(group *domain.Group, ips []*domain.IP) *GroupDTO {
 	dto := &GroupDTO{
 		ID:            group.ID,
@@ -193,6 +304,14 @@ func (uc *groupUseCase) mapGroupToDTO(group *domain.Group, ips []*domain.IP) *Gr
 		SpamTrapCount: group.SpamTrapCount,
 		IPsCount:      group.IPsCount,
 		IPs:           make([]IPDTO, 0),
+
+		AvgScore:           group.AvgScore,
+		MinScore:           group.MinScore,
+		WeightedScore:      group.WeightedScore,
+		LowScoreCount:      group.LowScoreCount,
+		CriticalScoreCount: group.CriticalScoreCount,
+		BlocklistedCount:   group.BlocklistedCount,
+		AvgComplaintRate:   group.AvgComplaintRate,
 	}
 
 	if ips != nil {
@@ -205,7 +324,11 @@ func (uc *groupUseCase) mapGroupToDTO(group *domain.Group, ips []*domain.IP) *Gr
 				SpamTrap:   ip.SpamTrap,
 				Blocklists: ip.Blocklists,
 				Complaints: ip.Complaints,
+				Status:     ip.Status,
 				UpdatedAt:  ip.UpdatedAt.Unix(),
+
+				BlocklistCount: ip.BlocklistCount,
+				ComplaintRate:  ip.ComplaintRate,
 			}
 		}
 	}
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"git.emercury.dev/emercury/senderscore/api/internal/domain"
@@ -11,29 +12,61 @@ import (
 type IPUseCase interface {
 	AddIP(ctx context.Context, dto AddIPDTO) (*IPDTO, error)
 	AddIPs(ctx context.Context, dtos []AddIPDTO) (*BatchIPResultDTO, error)
+	ImportIPs(ctx context.Context, dtos []AddIPDTO, emptyGroups []CreateGroupDTO) (*BatchIPResultDTO, error)
 	SubmitScore(ctx context.Context, dto SubmitScoreDTO) (*SubmitScoreResultDTO, error)
 	GetOldestIP(ctx context.Context) (*IPDTO, error)
+	ClaimStaleIPs(ctx context.Context, limit int, lease time.Duration) ([]*IPDTO, error)
+	ClaimIP(ctx context.Context, id uint, lease time.Duration) (bool, error)
+	ReleaseIP(ctx context.Context, id uint) error
+	ListAllIPs(ctx context.Context) ([]*IPDTO, error)
+	ListIPsByGroupID(ctx context.Context, groupID int) ([]*IPDTO, error)
+	ListScoreStats(ctx context.Context, ipAddress string) ([]*ScoreStatDTO, error)
+	GetHistory(ctx context.Context, ipAddress string, from, to time.Time) ([]HistoryEntryDTO, error)
+	GetIP(ctx context.Context, ipAddress string) (*IPDTO, error)
+	ListIPs(ctx context.Context, filter IPFilterDTO, pagination PaginationDTO) ([]*IPDTO, int64, error)
+	RemoveIPFromGroup(ctx context.Context, groupID int, ipAddress string) error
+	MoveIP(ctx context.Context, fromGroupID, toGroupID int, ipAddress string) error
+	ListEvents(ctx context.Context, ipAddress, field string) ([]*IPEventDTO, error)
 }
 
 type ipUseCase struct {
-	groupRepo   domain.GroupRepository
-	ipRepo      domain.IPRepository
-	historyRepo domain.HistoryRepository
+	groupRepo     domain.GroupRepository
+	ipRepo        domain.IPRepository
+	historyRepo   domain.HistoryRepository
+	scoreStatRepo domain.ScoreStatRepository
+	eventRepo     domain.IPEventRepository
+	uow           domain.UnitOfWork
+	alerter       Alerter
 }
 
+// NewIPUseCase creates the IP use case. alerter may be nil to disable alerts.
 func NewIPUseCase(
 	groupRepo domain.GroupRepository,
 	ipRepo domain.IPRepository,
 	historyRepo domain.HistoryRepository,
+	scoreStatRepo domain.ScoreStatRepository,
+	eventRepo domain.IPEventRepository,
+	uow domain.UnitOfWork,
+	alerter Alerter,
 ) IPUseCase {
 	return &ipUseCase{
-		groupRepo:   groupRepo,
-		ipRepo:      ipRepo,
-		historyRepo: historyRepo,
+		groupRepo:     groupRepo,
+		ipRepo:        ipRepo,
+		historyRepo:   historyRepo,
+		scoreStatRepo: scoreStatRepo,
+		eventRepo:     eventRepo,
+		uow:           uow,
+		alerter:       alerter,
 	}
 }
 
 func (uc *ipUseCase) AddIP(ctx context.Context, dto AddIPDTO) (*IPDTO, error) {
+	address, err := domain.CanonicalIP(dto.IP)
+	if err != nil {
+		return nil, err
+	}
+	dto.IP = address
+
 	existing, err := uc.ipRepo.GetByIP(ctx, dto.IP)
 	if err == nil && existing != nil {
 		if err := uc.ipRepo.AddToGroup(ctx, existing.ID, dto.GroupID); err != nil {
@@ -60,6 +93,7 @@ func (uc *ipUseCase) AddIP(ctx context.Context, dto AddIPDTO) (*IPDTO, error) {
 		SpamTrap:   0,
 		Blocklists: "",
 		Complaints: "",
+		Status:     domain.IPStatusPending,
 		UpdatedAt:  time.Now(),
 	}
 
@@ -78,139 +112,364 @@ func (uc *ipUseCase) AddIP(ctx context.Context, dto AddIPDTO) (*IPDTO, error) {
 	return uc.mapIPToDTO(ip), nil
 }
 
-func (uc *ipUseCase) AddIPs(ctx context.Context, dtos []AddIPDTO) (*BatchIPResultDTO, error) {
+// AddIPs adds the IPs to their groups in one transaction and reports the
+// outcome of every address. An item with CIDR set is expanded into every
+// address of the range. Invalid items are reported and skipped; if the
+// transaction fails, the remaining items are reported as failed and the
+// error is returned together with the result.
+func (uc *ipUseCase) AddIPs(ctx context.Context, items []AddIPDTO) (*BatchIPResultDTO, error) {
+	return uc.addIPs(ctx, items, nil)
+}
+
+// ImportIPs works like AddIPs and also creates the groups listed without
+// IPs, in the same transaction.
+func (uc *ipUseCase) ImportIPs(ctx context.Context, items []AddIPDTO, emptyGroups []CreateGroupDTO) (*BatchIPResultDTO, error) {
+	return uc.addIPs(ctx, items, emptyGroups)
+}
+
+func (uc *ipUseCase) addIPs(ctx context.Context, items []AddIPDTO, emptyGroups []CreateGroupDTO) (*BatchIPResultDTO, error) {
 	result := &BatchIPResultDTO{}
-	groupCache := make(map[int]bool)
+	groupNames := expandAddIPItems(items, result)
+	for _, group := range emptyGroups {
+		if groupNames[group.GroupID] == "" {
+			groupNames[group.GroupID] = group.GroupName
+		}
+	}
 
-	for _, dto := range dtos {
-		existing, err := uc.ipRepo.GetByIP(ctx, dto.IP)
-		if err == nil && existing != nil {
-			if err := uc.ipRepo.AddToGroup(ctx, existing.ID, dto.GroupID); err != nil {
-				return nil, fmt.Errorf("failed to add IP %s to group: %w", dto.IP, err)
-			}
-			result.IPsSkipped++
-		} else if err == domain.ErrIPNotFound {
-			if !groupCache[dto.GroupID] {
-				if err := uc.ensureGroupExists(ctx, dto.GroupID, dto.GroupName); err != nil {
-					if err != domain.ErrGroupAlreadyExists {
-						return nil, err
-					}
-				} else {
+	var valid []*AddIPItemResultDTO
+	for i := range result.Items {
+		if result.Items[i].Status == "" {
+			valid = append(valid, &result.Items[i])
+		}
+	}
+
+	var err error
+	if len(valid) > 0 || len(emptyGroups) > 0 {
+		err = uc.uow.Do(ctx, func(repos domain.Repositories) error {
+			for _, group := range emptyGroups {
+				created, err := ensureGroup(ctx, repos.Groups, group.GroupID, groupNames[group.GroupID])
+				if err != nil {
+					return err
+				}
+				if created {
 					result.GroupsCreated++
 				}
-				groupCache[dto.GroupID] = true
-			}
-
-			ip := &domain.IP{
-				IP:         dto.IP,
-				Score:      0,
-				SpamTrap:   0,
-				Blocklists: "",
-				Complaints: "",
-				UpdatedAt:  time.Now(),
 			}
-
-			if err := uc.ipRepo.Create(ctx, ip); err != nil {
-				return nil, fmt.Errorf("failed to create IP %s: %w", dto.IP, err)
-			}
-
-			if err := uc.ipRepo.AddToGroup(ctx, ip.ID, dto.GroupID); err != nil {
-				return nil, fmt.Errorf("failed to add IP %s to group: %w", dto.IP, err)
+			if len(valid) == 0 {
+				return nil
 			}
-
-			result.IPsCreated++
-		} else {
-			return nil, fmt.Errorf("failed to check IP %s: %w", dto.IP, err)
+			return addIPItems(ctx, repos, valid, groupNames, result)
+		})
+	}
+	if err != nil {
+		result.GroupsCreated = 0
+		for _, item := range valid {
+			item.Status = AddIPStatusFailed
+			item.Reason = "batch was rolled back"
 		}
+	}
 
-		if err := uc.groupRepo.UpdateCounters(ctx, dto.GroupID); err != nil {
-			return nil, fmt.Errorf("failed to update counters for group %d: %w", dto.GroupID, err)
+	for _, item := range result.Items {
+		switch item.Status {
+		case AddIPStatusCreated:
+			result.IPsCreated++
+		case AddIPStatusLinked:
+			result.IPsSkipped++
+		case AddIPStatusInvalid:
+			result.IPsInvalid++
+		case AddIPStatusFailed:
+			result.IPsFailed++
 		}
 	}
 
 	result.Message = fmt.Sprintf(
-		"groups created: %d; ips created: %d; ips skipped: %d",
+		"groups created: %d; ips created: %d; ips skipped: %d; ips invalid: %d; ips failed: %d",
 		result.GroupsCreated,
 		result.IPsCreated,
 		result.IPsSkipped,
+		result.IPsInvalid,
+		result.IPsFailed,
 	)
 
+	if err != nil {
+		return result, fmt.Errorf("failed to add IPs: %w", err)
+	}
 	return result, nil
 }
 
-func (uc *ipUseCase) SubmitScore(ctx context.Context, dto SubmitScoreDTO) (*SubmitScoreResultDTO, error) {
-	result := &SubmitScoreResultDTO{Success: true}
+// expandAddIPItems fills result.Items with one entry per address, marking
+// invalid items, and returns the group name given for every group.
+func expandAddIPItems(items []AddIPDTO, result *BatchIPResultDTO) map[int]string {
+	groupNames := make(map[int]string)
+	for i, item := range items {
+		if _, ok := groupNames[item.GroupID]; !ok || item.GroupName != "" {
+			groupNames[item.GroupID] = item.GroupName
+		}
+
+		entry := AddIPItemResultDTO{Index: i, GroupID: item.GroupID, IP: item.IP}
+		switch {
+		case item.IP != "" && item.CIDR != "":
+			entry.Status = AddIPStatusInvalid
+			entry.Reason = "only one of ip and cidr may be set"
+		case item.CIDR != "":
+			addresses, err := domain.ExpandCIDR(item.CIDR)
+			if err != nil {
+				entry.IP = item.CIDR
+				entry.Status = AddIPStatusInvalid
+				entry.Reason = err.Error()
+				break
+			}
+			for _, address := range addresses {
+				result.Items = append(result.Items, AddIPItemResultDTO{Index: i, GroupID: item.GroupID, IP: address})
+			}
+			continue
+		case item.IP == "":
+			entry.Status = AddIPStatusInvalid
+			entry.Reason = "ip or cidr is required"
+		default:
+			address, err := domain.CanonicalIP(item.IP)
+			if err != nil {
+				entry.Status = AddIPStatusInvalid
+				entry.Reason = err.Error()
+				break
+			}
+			entry.IP = address
+		}
+		result.Items = append(result.Items, entry)
+	}
+	return groupNames
+}
 
-	ip, err := uc.ipRepo.GetByIP(ctx, dto.IP)
-	if err == domain.ErrIPNotFound {
-		ip = &domain.IP{
-			IP:         dto.IP,
-			Score:      dto.Score,
-			SpamTrap:   dto.SpamTrap,
-			Blocklists: dto.Blocklists,
-			Complaints: dto.Complaints,
-			UpdatedAt:  time.Now(),
+// addIPItems creates the missing groups and IPs and links them with bulk
+// statements, then recomputes the counters of every touched group once.
+func addIPItems(ctx context.Context, repos domain.Repositories, items []*AddIPItemResultDTO, groupNames map[int]string, result *BatchIPResultDTO) error {
+	var groupIDs []int
+	touchedGroups := make(map[int]bool)
+	var addresses []string
+	ids := make(map[string]uint)
+	for _, item := range items {
+		if !touchedGroups[item.GroupID] {
+			touchedGroups[item.GroupID] = true
+			groupIDs = append(groupIDs, item.GroupID)
 		}
-		if err := uc.ipRepo.Create(ctx, ip); err != nil {
-			return nil, fmt.Errorf("failed to create IP: %w", err)
+		if _, ok := ids[item.IP]; !ok {
+			ids[item.IP] = 0
+			addresses = append(addresses, item.IP)
 		}
-		result.IPCreated = true
-	} else if err != nil {
-		return nil, fmt.Errorf("failed to check IP: %w", err)
-	} else {
-		ip.Score = dto.Score
-		ip.SpamTrap = dto.SpamTrap
-		ip.Blocklists = dto.Blocklists
-		ip.Complaints = dto.Complaints
-		ip.UpdatedAt = time.Now()
+	}
 
-		if err := uc.ipRepo.Update(ctx, ip); err != nil {
-			return nil, fmt.Errorf("failed to update IP: %w", err)
+	for _, groupID := range groupIDs {
+		created, err := ensureGroup(ctx, repos.Groups, groupID, groupNames[groupID])
+		if err != nil {
+			return err
+		}
+		if created {
+			result.GroupsCreated++
 		}
 	}
 
-	groupIDs, err := uc.groupRepo.GetGroupIDsByIP(ctx, ip.ID)
+	existing, err := repos.IPs.ListByAddresses(ctx, addresses)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get group IDs: %w", err)
+		return err
+	}
+	for _, ip := range existing {
+		ids[ip.IP] = ip.ID
+	}
+
+	var newIPs []*domain.IP
+	for _, address := range addresses {
+		if ids[address] != 0 {
+			continue
+		}
+		newIPs = append(newIPs, &domain.IP{
+			IP:        address,
+			Status:    domain.IPStatusPending,
+			UpdatedAt: time.Now(),
+		})
+	}
+	if err := repos.IPs.CreateBatch(ctx, newIPs); err != nil {
+		return err
+	}
+	created := make(map[string]bool, len(newIPs))
+	for _, ip := range newIPs {
+		ids[ip.IP] = ip.ID
+		created[ip.IP] = true
+	}
+
+	links := make([]domain.GroupLink, 0, len(items))
+	linked := make(map[domain.GroupLink]bool, len(items))
+	for _, item := range items {
+		link := domain.GroupLink{IPID: ids[item.IP], GroupID: item.GroupID}
+		if !linked[link] {
+			linked[link] = true
+			links = append(links, link)
+		}
+
+		// Повторное упоминание нового IP в пакете только связывает его с группой
+		if created[item.IP] {
+			item.Status = AddIPStatusCreated
+			created[item.IP] = false
+		} else {
+			item.Status = AddIPStatusLinked
+		}
+	}
+	if err := repos.IPs.AddToGroups(ctx, links); err != nil {
+		return err
 	}
 
 	for _, groupID := range groupIDs {
-		if err := uc.groupRepo.UpdateCounters(ctx, groupID); err != nil {
-			return nil, fmt.Errorf("failed to update counters for group %d: %w", groupID, err)
+		if err := repos.Groups.UpdateCounters(ctx, groupID); err != nil {
+			return fmt.Errorf("failed to update counters for group %d: %w", groupID, err)
 		}
 	}
+	return nil
+}
+
+// ensureGroup creates the group if it does not exist yet and reports
+// whether it did.
+func ensureGroup(ctx context.Context, groupRepo domain.GroupRepository, groupID int, groupName string) (bool, error) {
+	_, err := groupRepo.GetByGroupID(ctx, groupID)
+	if err == nil {
+		return false, nil
+	}
+	if err != domain.ErrGroupNotFound {
+		return false, fmt.Errorf("failed to check group: %w", err)
+	}
+
+	if err := groupRepo.Create(ctx, &domain.Group{GroupID: groupID, GroupName: groupName}); err != nil {
+		return false, fmt.Errorf("failed to create group: %w", err)
+	}
+	return true, nil
+}
+
+func (uc *ipUseCase) SubmitScore(ctx context.Context, dto SubmitScoreDTO) (*SubmitScoreResultDTO, error) {
+	address, err := domain.CanonicalIP(dto.IP)
+	if err != nil {
+		return nil, err
+	}
+	dto.IP = address
 
+	observedAt := dto.ObservedAt
+	if observedAt.IsZero() {
+		observedAt = time.Now()
+	}
+
+	if dto.NoData {
+		return uc.submitNoData(ctx, dto.IP, observedAt)
+	}
+
+	histories := make([]*domain.History, 0, len(dto.History))
+	byDate := make(map[string]int, len(dto.History))
 	for _, histEntry := range dto.History {
 		date, err := time.Parse("02.01.2006", histEntry.Date)
 		if err != nil {
 			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidDateFormat, histEntry.Date)
 		}
 
-		existing, err := uc.historyRepo.GetByIPAndDate(ctx, ip.ID, histEntry.Date)
-		if err == domain.ErrGroupNotFound {
-			history := &domain.History{
-				IPsID:    ip.ID,
-				Score:    histEntry.Score,
-				SpamTrap: histEntry.SpamTrap,
-				Volume:   histEntry.Volume,
-				Time:     date,
+		history := &domain.History{
+			Score:    histEntry.Score,
+			SpamTrap: histEntry.SpamTrap,
+			Volume:   histEntry.Volume,
+			Time:     date,
+		}
+		// Одна дата не может обновиться дважды в одном INSERT ... ON CONFLICT,
+		// поэтому побеждает последняя запись
+		if i, ok := byDate[histEntry.Date]; ok {
+			histories[i] = history
+			continue
+		}
+		byDate[histEntry.Date] = len(histories)
+		histories = append(histories, history)
+	}
+
+	result := &SubmitScoreResultDTO{Success: true}
+
+	var ip, previous *domain.IP
+	var groupIDs []int
+	stale := false
+	err = uc.uow.Do(ctx, func(repos domain.Repositories) error {
+		var err error
+		ip, err = repos.IPs.GetByIP(ctx, dto.IP)
+		if err == domain.ErrIPNotFound {
+			ip = &domain.IP{
+				IP:         dto.IP,
+				Score:      dto.Score,
+				SpamTrap:   dto.SpamTrap,
+				Blocklists: dto.Blocklists,
+				Complaints: dto.Complaints,
+				Status:     domain.IPStatusOK,
+				UpdatedAt:  observedAt,
 			}
-			if err := uc.historyRepo.Create(ctx, history); err != nil {
-				return nil, fmt.Errorf("failed to create history: %w", err)
+			ip.BlocklistCount = dto.BlocklistCount
+			ip.ComplaintRate = dto.ComplaintRate
+			if err := repos.IPs.Create(ctx, ip); err != nil {
+				return fmt.Errorf("failed to create IP: %w", err)
 			}
-			result.HistoryAdded++
+			result.IPCreated = true
 		} else if err != nil {
-			return nil, fmt.Errorf("failed to check history: %w", err)
+			return fmt.Errorf("failed to check IP: %w", err)
+		} else if isStaleReport(ip, observedAt) {
+			// Архивный отчет старше сохраненного состояния: дополняется только
+			// история, текущее состояние IP не откатывается
+			stale = true
 		} else {
-			existing.Score = histEntry.Score
-			existing.SpamTrap = histEntry.SpamTrap
-			existing.Volume = histEntry.Volume
+			prev := *ip
+			previous = &prev
+
+			ip.Score = dto.Score
+			ip.SpamTrap = dto.SpamTrap
+			ip.Blocklists = dto.Blocklists
+			ip.Complaints = dto.Complaints
+			ip.BlocklistCount = dto.BlocklistCount
+			ip.ComplaintRate = dto.ComplaintRate
+			ip.Status = domain.IPStatusOK
+			ip.UpdatedAt = observedAt
+
+			if err := repos.IPs.Update(ctx, ip); err != nil {
+				return fmt.Errorf("failed to update IP: %w", err)
+			}
+		}
+
+		if err := recordScoreStat(ctx, repos.ScoreStats, ip.ID, dto.Score, domain.ScoreResultFor(dto.Score), observedAt); err != nil {
+			return err
+		}
+
+		if !stale {
+			if err := recordEvents(ctx, repos.Events, ip, previous, observedAt); err != nil {
+				return err
+			}
+		}
+
+		for _, history := range histories {
+			history.IPsID = ip.ID
+		}
+		added, err := repos.History.Upsert(ctx, histories)
+		if err != nil {
+			return fmt.Errorf("failed to save history: %w", err)
+		}
+		result.HistoryAdded = added
+		result.HistoryUpdated = len(histories) - added
+
+		// Счетчики пересчитываются после истории: weighted_score читает объемы из нее
+		groupIDs, err = repos.Groups.GetGroupIDsByIP(ctx, ip.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get group IDs: %w", err)
+		}
 
-			if err := uc.historyRepo.Update(ctx, existing); err != nil {
-				return nil, fmt.Errorf("failed to update history: %w", err)
+		for _, groupID := range groupIDs {
+			if err := repos.Groups.UpdateCounters(ctx, groupID); err != nil {
+				return fmt.Errorf("failed to update counters for group %d: %w", groupID, err)
 			}
-			result.HistoryUpdated++
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.alerter != nil && !stale {
+		ip.GroupIDs = groupIDs
+		uc.alerter.Evaluate(ctx, ip, previous)
 	}
 
 	result.Message = fmt.Sprintf(
@@ -223,6 +482,130 @@ func (uc *ipUseCase) SubmitScore(ctx context.Context, dto SubmitScoreDTO) (*Subm
 	return result, nil
 }
 
+// submitNoData marks the IP as having no upstream data while keeping its
+// last known score and history.
+func (uc *ipUseCase) submitNoData(ctx context.Context, ipAddress string, observedAt time.Time) (*SubmitScoreResultDTO, error) {
+	result := &SubmitScoreResultDTO{Success: true}
+
+	err := uc.uow.Do(ctx, func(repos domain.Repositories) error {
+		ip, err := repos.IPs.GetByIP(ctx, ipAddress)
+		if err == domain.ErrIPNotFound {
+			ip = &domain.IP{
+				IP:        ipAddress,
+				Status:    domain.IPStatusNoData,
+				UpdatedAt: observedAt,
+			}
+			if err := repos.IPs.Create(ctx, ip); err != nil {
+				return fmt.Errorf("failed to create IP: %w", err)
+			}
+			result.IPCreated = true
+		} else if err != nil {
+			return fmt.Errorf("failed to check IP: %w", err)
+		} else if !isStaleReport(ip, observedAt) {
+			ip.Status = domain.IPStatusNoData
+			ip.UpdatedAt = observedAt
+
+			if err := repos.IPs.Update(ctx, ip); err != nil {
+				return fmt.Errorf("failed to update IP: %w", err)
+			}
+		}
+
+		return recordScoreStat(ctx, repos.ScoreStats, ip.ID, 0, domain.ScoreResultNoData, observedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Message = fmt.Sprintf("No data available. IP created: %t", result.IPCreated)
+
+	return result, nil
+}
+
+// isStaleReport reports whether a report observed at observedAt is older
+// than the stored state of ip, as when archived reports are re-applied.
+func isStaleReport(ip *domain.IP, observedAt time.Time) bool {
+	return ip.Status != domain.IPStatusPending && observedAt.Before(ip.UpdatedAt)
+}
+
+func recordScoreStat(ctx context.Context, scoreStatRepo domain.ScoreStatRepository, ipID uint, score, result int, observedAt time.Time) error {
+	stat := &domain.ScoreStat{
+		IPsID:      ipID,
+		Score:      score,
+		Result:     result,
+		Date:       observedAt,
+		ObservedAt: observedAt,
+	}
+	if err := scoreStatRepo.Create(ctx, stat); err != nil {
+		return fmt.Errorf("failed to record score stat: %w", err)
+	}
+	return nil
+}
+
+// recordEvents appends an event for every tracked field that differs from
+// the previous state. A missing or pending previous state has no values.
+func recordEvents(ctx context.Context, eventRepo domain.IPEventRepository, current, previous *domain.IP, observedAt time.Time) error {
+	hasPrevious := previous != nil && previous.Status != domain.IPStatusPending
+	if !hasPrevious {
+		previous = &domain.IP{}
+	}
+
+	var events []*domain.IPEvent
+	for _, f := range []struct {
+		field    string
+		old, new string
+	}{
+		{domain.IPEventFieldScore, strconv.Itoa(previous.Score), strconv.Itoa(current.Score)},
+		{domain.IPEventFieldSpamTrap, strconv.Itoa(previous.SpamTrap), strconv.Itoa(current.SpamTrap)},
+		{domain.IPEventFieldBlocklists, previous.Blocklists, current.Blocklists},
+		{domain.IPEventFieldComplaints, previous.Complaints, current.Complaints},
+	} {
+		if hasPrevious && f.old == f.new {
+			continue
+		}
+
+		event := &domain.IPEvent{
+			IPsID:     current.ID,
+			Field:     f.field,
+			OldValue:  f.old,
+			NewValue:  f.new,
+			CreatedAt: observedAt,
+		}
+		if !hasPrevious {
+			event.OldValue = ""
+		}
+		events = append(events, event)
+	}
+
+	if err := eventRepo.Create(ctx, events); err != nil {
+		return fmt.Errorf("failed to record IP events: %w", err)
+	}
+	return nil
+}
+
+func (uc *ipUseCase) ListEvents(ctx context.Context, ipAddress, field string) ([]*IPEventDTO, error) {
+	ip, err := uc.ipRepo.GetByIP(ctx, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := uc.eventRepo.ListByIPID(ctx, ip.ID, field)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*IPEventDTO, len(events))
+	for i, event := range events {
+		result[i] = &IPEventDTO{
+			ID:        event.ID,
+			Field:     event.Field,
+			OldValue:  event.OldValue,
+			NewValue:  event.NewValue,
+			CreatedAt: event.CreatedAt.Unix(),
+		}
+	}
+	return result, nil
+}
+
 func (uc *ipUseCase) GetOldestIP(ctx context.Context) (*IPDTO, error) {
 	ip, err := uc.ipRepo.GetOldestIP(ctx)
 	if err != nil {
@@ -231,6 +614,204 @@ func (uc *ipUseCase) GetOldestIP(ctx context.Context) (*IPDTO, error) {
 	return uc.mapIPToDTO(ip), nil
 }
 
+func (uc *ipUseCase) ClaimStaleIPs(ctx context.Context, limit int, lease time.Duration) ([]*IPDTO, error) {
+	ips, err := uc.ipRepo.ClaimStale(ctx, limit, lease)
+	if err != nil {
+		return nil, err
+	}
+	return uc.mapIPsToDTO(ips), nil
+}
+
+func (uc *ipUseCase) ClaimIP(ctx context.Context, id uint, lease time.Duration) (bool, error) {
+	return uc.ipRepo.Claim(ctx, id, lease)
+}
+
+func (uc *ipUseCase) ReleaseIP(ctx context.Context, id uint) error {
+	return uc.ipRepo.ReleaseClaim(ctx, id)
+}
+
+func (uc *ipUseCase) ListAllIPs(ctx context.Context) ([]*IPDTO, error) {
+	ips, err := uc.ipRepo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return uc.mapIPsToDTO(ips), nil
+}
+
+func (uc *ipUseCase) ListIPsByGroupID(ctx context.Context, groupID int) ([]*IPDTO, error) {
+	if _, err := uc.groupRepo.GetByGroupID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	ips, err := uc.ipRepo.ListByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.mapIPsToDTO(ips), nil
+}
+
+func (uc *ipUseCase) ListScoreStats(ctx context.Context, ipAddress string) ([]*ScoreStatDTO, error) {
+	ip, err := uc.ipRepo.GetByIP(ctx, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := uc.scoreStatRepo.ListByIPID(ctx, ip.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*ScoreStatDTO, len(stats))
+	for i, stat := range stats {
+		result[i] = &ScoreStatDTO{
+			ID:     stat.ID,
+			Score:  stat.Score,
+			Result: stat.Result,
+			Date:   stat.Date.Format("02.01.2006"),
+		}
+	}
+	return result, nil
+}
+
+func (uc *ipUseCase) GetHistory(ctx context.Context, ipAddress string, from, to time.Time) ([]HistoryEntryDTO, error) {
+	ip, err := uc.ipRepo.GetByIP(ctx, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	histories, err := uc.historyRepo.ListByIPIDAndRange(ctx, ip.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]HistoryEntryDTO, len(histories))
+	for i, history := range histories {
+		result[i] = HistoryEntryDTO{
+			Date:     history.Time.Format("02.01.2006"),
+			Score:    history.Score,
+			Volume:   history.Volume,
+			SpamTrap: history.SpamTrap,
+		}
+	}
+	return result, nil
+}
+
+func (uc *ipUseCase) GetIP(ctx context.Context, ipAddress string) (*IPDTO, error) {
+	ip, err := uc.ipRepo.GetByIP(ctx, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+	return uc.mapIPToDTO(ip), nil
+}
+
+func (uc *ipUseCase) ListIPs(ctx context.Context, filter IPFilterDTO, pagination PaginationDTO) ([]*IPDTO, int64, error) {
+	pagination = pagination.Normalize()
+
+	ips, total, err := uc.ipRepo.List(ctx, domain.IPFilter{
+		GroupID:      filter.GroupID,
+		Search:       filter.Search,
+		Status:       filter.Status,
+		MinScore:     filter.MinScore,
+		MaxScore:     filter.MaxScore,
+		HasSpamTraps: filter.HasSpamTraps,
+
+		Blocklisted:      filter.Blocklisted,
+		MinComplaintRate: filter.MinComplaintRate,
+	}, pagination.Offset(), pagination.PageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list IPs: %w", err)
+	}
+
+	return uc.mapIPsToDTO(ips), total, nil
+}
+
+// RemoveIPFromGroup unlinks the IP from the group and deletes it together
+// with its history when no other group references it.
+func (uc *ipUseCase) RemoveIPFromGroup(ctx context.Context, groupID int, ipAddress string) error {
+	ip, err := uc.getIPInGroup(ctx, groupID, ipAddress)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.ipRepo.RemoveFromGroup(ctx, ip.ID, groupID); err != nil {
+		return fmt.Errorf("failed to remove IP from group: %w", err)
+	}
+
+	hasOtherGroups, err := uc.ipRepo.IsIPInOtherGroups(ctx, ip.ID, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to check if IP is in other groups: %w", err)
+	}
+
+	if !hasOtherGroups {
+		if err := uc.historyRepo.DeleteByIPID(ctx, ip.ID); err != nil {
+			return fmt.Errorf("failed to delete history: %w", err)
+		}
+
+		if err := uc.scoreStatRepo.DeleteByIPID(ctx, ip.ID); err != nil {
+			return fmt.Errorf("failed to delete stats: %w", err)
+		}
+
+		if err := uc.ipRepo.Delete(ctx, ip.ID); err != nil {
+			return fmt.Errorf("failed to delete IP: %w", err)
+		}
+	}
+
+	if err := uc.groupRepo.UpdateCounters(ctx, groupID); err != nil {
+		return fmt.Errorf("failed to update group counters: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *ipUseCase) MoveIP(ctx context.Context, fromGroupID, toGroupID int, ipAddress string) error {
+	ip, err := uc.getIPInGroup(ctx, fromGroupID, ipAddress)
+	if err != nil {
+		return err
+	}
+
+	if fromGroupID == toGroupID {
+		return nil
+	}
+
+	if _, err := uc.groupRepo.GetByGroupID(ctx, toGroupID); err != nil {
+		return err
+	}
+
+	if err := uc.ipRepo.AddToGroup(ctx, ip.ID, toGroupID); err != nil {
+		return fmt.Errorf("failed to add IP to group: %w", err)
+	}
+
+	if err := uc.ipRepo.RemoveFromGroup(ctx, ip.ID, fromGroupID); err != nil {
+		return fmt.Errorf("failed to remove IP from group: %w", err)
+	}
+
+	for _, groupID := range []int{fromGroupID, toGroupID} {
+		if err := uc.groupRepo.UpdateCounters(ctx, groupID); err != nil {
+			return fmt.Errorf("failed to update counters for group %d: %w", groupID, err)
+		}
+	}
+
+	return nil
+}
+
+func (uc *ipUseCase) getIPInGroup(ctx context.Context, groupID int, ipAddress string) (*domain.IP, error) {
+	if _, err := uc.groupRepo.GetByGroupID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	ip, err := uc.ipRepo.GetByIP(ctx, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ip.GroupIDs {
+		if id == groupID {
+			return ip, nil
+		}
+	}
+	return nil, domain.ErrIPNotInGroup
+}
+
 func (uc *ipUseCase) ensureGroupExists(ctx context.Context, groupID int, groupName string) error {
 	_, err := uc.groupRepo.GetByGroupID(ctx, groupID)
 	if err == nil {
@@ -262,6 +843,19 @@ func (uc *ipUseCase) mapIPToDTO(ip *domain.IP) *IPDTO {
 		SpamTrap:   ip.SpamTrap,
 		Blocklists: ip.Blocklists,
 		Complaints: ip.Complaints,
+		Status:     ip.Status,
 		UpdatedAt:  ip.UpdatedAt.Unix(),
+		GroupIDs:   ip.GroupIDs,
+
+		BlocklistCount: ip.BlocklistCount,
+		ComplaintRate:  ip.ComplaintRate,
+	}
+}
+
+func (uc *ipUseCase) mapIPsToDTO(ips []*domain.IP) []*IPDTO {
+	result := make([]*IPDTO, len(ips))
+	for i, ip := range ips {
+		result[i] = uc.mapIPToDTO(ip)
 	}
+	return result
 }
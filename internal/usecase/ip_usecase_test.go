@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	"git.emercury.dev/emercury/senderscore/api/internal/domain"
+)
+
+// fakeStore хранит группы, IP и связи, общие для репозиториев одной
+// транзакции. failOn задает метод, который вернет ошибку.
+type fakeStore struct {
+	groups  map[int]*domain.Group
+	ips     map[string]*domain.IP
+	links   map[domain.GroupLink]bool
+	nextID  uint
+	updated []int
+	failOn  string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		groups: make(map[int]*domain.Group),
+		ips:    make(map[string]*domain.IP),
+		links:  make(map[domain.GroupLink]bool),
+	}
+}
+
+func (s *fakeStore) fail(method string) error {
+	if s.failOn == method {
+		return errors.New(method + " failed")
+	}
+	return nil
+}
+
+type fakeGroupRepository struct {
+	domain.GroupRepository
+	store *fakeStore
+}
+
+func (r *fakeGroupRepository) GetByGroupID(ctx context.Context, groupID int) (*domain.Group, error) {
+	group, ok := r.store.groups[groupID]
+	if !ok {
+		return nil, domain.ErrGroupNotFound
+	}
+	return group, nil
+}
+
+func (r *fakeGroupRepository) Create(ctx context.Context, group *domain.Group) error {
+	if err := r.store.fail("Groups.Create"); err != nil {
+		return err
+	}
+	r.store.nextID++
+	group.ID = r.store.nextID
+	r.store.groups[group.GroupID] = group
+	return nil
+}
+
+func (r *fakeGroupRepository) UpdateCounters(ctx context.Context, groupID int) error {
+	r.store.updated = append(r.store.updated, groupID)
+	return nil
+}
+
+type fakeIPRepository struct {
+	domain.IPRepository
+	store *fakeStore
+}
+
+func (r *fakeIPRepository) ListByAddresses(ctx context.Context, addresses []string) ([]*domain.IP, error) {
+	var ips []*domain.IP
+	for _, address := range addresses {
+		if ip, ok := r.store.ips[address]; ok {
+			ips = append(ips, ip)
+		}
+	}
+	return ips, nil
+}
+
+func (r *fakeIPRepository) CreateBatch(ctx context.Context, ips []*domain.IP) error {
+	for _, ip := range ips {
+		if _, ok := r.store.ips[ip.IP]; ok {
+			return errors.New("duplicate ip " + ip.IP)
+		}
+		r.store.nextID++
+		ip.ID = r.store.nextID
+		r.store.ips[ip.IP] = ip
+	}
+	return nil
+}
+
+func (r *fakeIPRepository) AddToGroups(ctx context.Context, links []domain.GroupLink) error {
+	if err := r.store.fail("IPs.AddToGroups"); err != nil {
+		return err
+	}
+	for _, link := range links {
+		r.store.links[link] = true
+	}
+	return nil
+}
+
+// fakeUnitOfWork откатывает изменения fakeStore, если fn вернула ошибку.
+type fakeUnitOfWork struct {
+	store *fakeStore
+	calls int
+}
+
+func (u *fakeUnitOfWork) Do(ctx context.Context, fn func(repos domain.Repositories) error) error {
+	u.calls++
+	groups, ips, links := maps.Clone(u.store.groups), maps.Clone(u.store.ips), maps.Clone(u.store.links)
+	nextID, updated := u.store.nextID, len(u.store.updated)
+
+	err := fn(domain.Repositories{
+		Groups: &fakeGroupRepository{store: u.store},
+		IPs:    &fakeIPRepository{store: u.store},
+	})
+	if err != nil {
+		u.store.groups, u.store.ips, u.store.links = groups, ips, links
+		u.store.nextID, u.store.updated = nextID, u.store.updated[:updated]
+	}
+	return err
+}
+
+// newAddIPsUseCase возвращает usecase с группой 1, в которой уже есть 192.0.2.1.
+func newAddIPsUseCase() (*ipUseCase, *fakeStore, *fakeUnitOfWork) {
+	store := newFakeStore()
+	store.groups[1] = &domain.Group{ID: 1, GroupID: 1, GroupName: "existing"}
+	store.ips["192.0.2.1"] = &domain.IP{ID: 2, IP: "192.0.2.1"}
+	store.links[domain.GroupLink{IPID: 2, GroupID: 1}] = true
+	store.nextID = 2
+
+	uow := &fakeUnitOfWork{store: store}
+	return NewIPUseCase(nil, nil, nil, nil, nil, uow, nil).(*ipUseCase), store, uow
+}
+
+func itemStatuses(result *BatchIPResultDTO) []string {
+	statuses := make([]string, len(result.Items))
+	for i, item := range result.Items {
+		statuses[i] = item.IP + " " + item.Status
+	}
+	return statuses
+}
+
+func TestAddIPsItemStatuses(t *testing.T) {
+	uc, store, _ := newAddIPsUseCase()
+
+	result, err := uc.AddIPs(context.Background(), []AddIPDTO{
+		{GroupID: 1, IP: "192.0.2.1"},
+		{GroupID: 1, IP: "192.0.2.2"},
+		{GroupID: 2, GroupName: "new", IP: "192.0.2.2"},
+		{GroupID: 2, IP: "::ffff:192.0.2.2"},
+		{GroupID: 1, IP: "192.0.2.300"},
+		{GroupID: 1, IP: "192.0.2.3", CIDR: "192.0.2.0/30"},
+		{GroupID: 1},
+		{GroupID: 3, CIDR: "192.0.2.8/30"},
+	})
+	if err != nil {
+		t.Fatalf("AddIPs() unexpected error: %v", err)
+	}
+
+	want := []string{
+		"192.0.2.1 " + AddIPStatusLinked,
+		"192.0.2.2 " + AddIPStatusCreated,
+		"192.0.2.2 " + AddIPStatusLinked,
+		"192.0.2.2 " + AddIPStatusLinked,
+		"192.0.2.300 " + AddIPStatusInvalid,
+		"192.0.2.3 " + AddIPStatusInvalid,
+		" " + AddIPStatusInvalid,
+		"192.0.2.9 " + AddIPStatusCreated,
+		"192.0.2.10 " + AddIPStatusCreated,
+	}
+	got := itemStatuses(result)
+	if len(got) != len(want) {
+		t.Fatalf("items = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("item %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if result.GroupsCreated != 2 || result.IPsCreated != 3 || result.IPsSkipped != 3 || result.IPsInvalid != 3 || result.IPsFailed != 0 {
+		t.Errorf("result = %+v, want 2 groups, 3 created, 3 skipped, 3 invalid", result)
+	}
+	if group := store.groups[2]; group == nil || group.GroupName != "new" {
+		t.Errorf("group 2 = %+v, want it created with name new", group)
+	}
+
+	id := store.ips["192.0.2.2"].ID
+	if !store.links[domain.GroupLink{IPID: id, GroupID: 1}] || !store.links[domain.GroupLink{IPID: id, GroupID: 2}] {
+		t.Errorf("links = %v, want 192.0.2.2 in groups 1 and 2", store.links)
+	}
+	if len(store.updated) != 3 {
+		t.Errorf("counters updated for %v, want each of groups 1, 2 and 3 once", store.updated)
+	}
+}
+
+func TestAddIPsRollsBackOnError(t *testing.T) {
+	uc, store, _ := newAddIPsUseCase()
+	store.failOn = "IPs.AddToGroups"
+
+	result, err := uc.AddIPs(context.Background(), []AddIPDTO{
+		{GroupID: 2, IP: "192.0.2.2"},
+		{GroupID: 1, IP: "192.0.2.1"},
+		{GroupID: 1, IP: "not an ip"},
+	})
+	if err == nil {
+		t.Fatal("AddIPs() error = nil, want the repository error")
+	}
+
+	want := []string{
+		"192.0.2.2 " + AddIPStatusFailed,
+		"192.0.2.1 " + AddIPStatusFailed,
+		"not an ip " + AddIPStatusInvalid,
+	}
+	got := itemStatuses(result)
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] {
+			t.Fatalf("items = %v, want %v", got, want)
+		}
+	}
+	if result.GroupsCreated != 0 || result.IPsFailed != 2 || result.IPsInvalid != 1 {
+		t.Errorf("result = %+v, want no groups created, 2 failed, 1 invalid", result)
+	}
+	if _, ok := store.groups[2]; ok {
+		t.Error("group 2 was kept after rollback")
+	}
+	if _, ok := store.ips["192.0.2.2"]; ok {
+		t.Error("192.0.2.2 was kept after rollback")
+	}
+}
+
+func TestAddIPsWithoutValidItems(t *testing.T) {
+	uc, _, uow := newAddIPsUseCase()
+
+	result, err := uc.AddIPs(context.Background(), []AddIPDTO{{GroupID: 1, IP: "bad"}})
+	if err != nil {
+		t.Fatalf("AddIPs() unexpected error: %v", err)
+	}
+	if result.IPsInvalid != 1 || uow.calls != 0 {
+		t.Errorf("result = %+v, transactions = %d; want 1 invalid and no transaction", result, uow.calls)
+	}
+}
+
+func TestImportIPsCreatesEmptyGroupsInTransaction(t *testing.T) {
+	uc, store, _ := newAddIPsUseCase()
+	items := []AddIPDTO{{GroupID: 1, IP: "192.0.2.2"}}
+	emptyGroups := []CreateGroupDTO{{GroupID: 4, GroupName: "empty"}, {GroupID: 1, GroupName: "existing"}}
+
+	store.failOn = "IPs.AddToGroups"
+	if _, err := uc.ImportIPs(context.Background(), items, emptyGroups); err == nil {
+		t.Fatal("ImportIPs() error = nil, want the repository error")
+	}
+	if _, ok := store.groups[4]; ok {
+		t.Fatal("empty group was created although the import failed")
+	}
+
+	store.failOn = ""
+	result, err := uc.ImportIPs(context.Background(), items, emptyGroups)
+	if err != nil {
+		t.Fatalf("ImportIPs() unexpected error: %v", err)
+	}
+	if group := store.groups[4]; group == nil || group.GroupName != "empty" {
+		t.Errorf("group 4 = %+v, want it created with name empty", group)
+	}
+	if result.GroupsCreated != 1 || result.IPsCreated != 1 {
+		t.Errorf("result = %+v, want 1 group and 1 IP created", result)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -11,10 +12,16 @@ import (
 )
 
 type Config struct {
-	DB      DatabaseConfig `envconfig:"DB"`
-	Logging LoggingConfig  `envconfig:"LOG"`
-	Auth    AuthConfig     `envconfig:"AUTH"`
-	Server  ServerConfig   `envconfig:"SERVER"`
+	DB       DatabaseConfig `envconfig:"DB"`
+	Logging  LoggingConfig  `envconfig:"LOG"`
+	Auth     AuthConfig     `envconfig:"AUTH"`
+	Server   ServerConfig   `envconfig:"SERVER"`
+	Worker   WorkerConfig   `envconfig:"WORKER"`
+	Provider ProviderConfig `envconfig:"PROVIDER"`
+	Archive  ArchiveConfig  `envconfig:"ARCHIVE"`
+	Group    GroupConfig    `envconfig:"GROUP"`
+	Alert    AlertConfig    `envconfig:"ALERT"`
+	SMTP     SMTPConfig     `envconfig:"SMTP"`
 }
 
 type DatabaseConfig struct {
@@ -33,18 +40,67 @@ type ServerConfig struct {
 	Port string `envconfig:"PORT" default:"8080"`
 }
 
+type WorkerConfig struct {
+	Interval     time.Duration `envconfig:"INTERVAL" default:"1m"`
+	BatchSize    int           `envconfig:"BATCH_SIZE" default:"10"`
+	Lease        time.Duration `envconfig:"LEASE" default:"30m"`
+	FetchTimeout time.Duration `envconfig:"FETCH_TIMEOUT" default:"2m"`
+}
+
+type ProviderConfig struct {
+	Name string `envconfig:"NAME" default:"senderscore"`
+	Dir  string `envconfig:"DIR"`
+}
+
+type ArchiveConfig struct {
+	Backend string `envconfig:"BACKEND" default:"db"`
+	Dir     string `envconfig:"DIR"`
+}
+
+type GroupConfig struct {
+	LowScoreThreshold      int `envconfig:"LOW_SCORE_THRESHOLD" default:"80"`
+	CriticalScoreThreshold int `envconfig:"CRITICAL_SCORE_THRESHOLD" default:"70"`
+}
+
+type AlertConfig struct {
+	ScoreBelow     int           `envconfig:"SCORE_BELOW" default:"70"`
+	ScoreDrop      int           `envconfig:"SCORE_DROP" default:"10"`
+	SpamTraps      bool          `envconfig:"SPAM_TRAPS" default:"true"`
+	Blocklisted    bool          `envconfig:"BLOCKLISTED" default:"true"`
+	WebhookURLs    string        `envconfig:"WEBHOOK_URLS"`
+	WebhookSecret  string        `envconfig:"WEBHOOK_SECRET"`
+	WebhookTimeout time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`
+	GroupChannels  bool          `envconfig:"GROUP_CHANNELS" default:"false"`
+}
+
+type SMTPConfig struct {
+	Host     string `envconfig:"HOST"`
+	Port     int    `envconfig:"PORT" default:"25"`
+	Username string `envconfig:"USERNAME"`
+	Password string `envconfig:"PASSWORD"`
+	From     string `envconfig:"FROM" default:"senderscore@localhost"`
+}
+
+func (a *AlertConfig) GetWebhookURLs() []string {
+	return splitList(a.WebhookURLs)
+}
+
 func (a *AuthConfig) GetTokens() []string {
-	if a.APITokens == "" {
+	return splitList(a.APITokens)
+}
+
+func splitList(value string) []string {
+	if value == "" {
 		return []string{}
 	}
 
-	tokens := strings.Split(a.APITokens, ",")
-	result := make([]string, 0, len(tokens))
+	items := strings.Split(value, ",")
+	result := make([]string, 0, len(items))
 
-	for _, token := range tokens {
-		token = strings.TrimSpace(token)
-		if token != "" {
-			result = append(result, token)
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
 		}
 	}
 